@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// AbortUploadsCommand represents the options that can be passed to the abort-uploads subcommand.
+type AbortUploadsCommand struct {
+	Bucket      string        `short:"b" long:"bucket" value-name:"bucket" description:"the bucket to clean up" required:"true"`
+	Prefixes    []string      `long:"prefix" value-name:"prefix" description:"only abort uploads to keys under this prefix, may be repeated" required:"false"`
+	OlderThan   parser.Cutoff `long:"older-than" value-name:"age" description:"only abort uploads initiated before this age (90d, 2w, 36h) or RFC3339 timestamp" required:"false"`
+	DryRun      bool          `long:"dry-run" description:"list the uploads that would be aborted without aborting anything" required:"false"`
+	Concurrency int           `short:"c" long:"concurrency" value-name:"n" description:"number of ListParts and AbortMultipartUpload requests to run in parallel" required:"false" default:"4"`
+	MaxRPS      float64       `long:"max-rps" value-name:"n" description:"send at most this many requests per second, concurrency also drops by itself while S3 answers with SlowDown" required:"false"`
+	Yes         bool          `short:"y" long:"yes" description:"do not ask for confirmation, required when stdin is not a terminal" required:"false"`
+}
+
+func init() {
+	var cmd AbortUploadsCommand
+
+	// nolint [:errcheck]
+	parser.OptParser.AddCommand(
+		"abort-uploads",
+		"Abort incomplete multipart uploads in an s3 bucket",
+		"List the multipart uploads in progress in an s3 bucket, with the parts they hold, and abort them to free the storage",
+		&cmd,
+	)
+}
+
+// Execute implements the interface for the go-flags subcommand.
+func (cmd *AbortUploadsCommand) Execute(args []string) error {
+	if !cmd.DryRun {
+		if err := checkInteractive(cmd.Yes); err != nil {
+			return err
+		}
+	}
+
+	region := bucketRegion(cmd.Bucket)
+	awsSession := aws.Client.GetRegionSession(region)
+	s3client := s3svc.NewClient(
+		s3.New(awsSession),
+		parser.GlobalOpts.Verbose,
+		s3svc.WithConcurrency(cmd.Concurrency),
+		s3svc.WithMaxRPS(cmd.MaxRPS),
+	)
+
+	listed, err := s3client.ListUploads(cmd.Bucket, cmd.Prefixes...)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => method call s3svc.Client.ListUploads failed\n")
+	}
+
+	uploads := []*s3svc.Upload{}
+	for _, upload := range listed {
+		if cmd.OlderThan.IsZero() || upload.Initiated.Before(cmd.OlderThan.Time) {
+			uploads = append(uploads, upload)
+		}
+	}
+
+	if len(uploads) == 0 {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "No multipart uploads to abort in s3://%s\n", cmd.Bucket)
+		return nil
+	}
+
+	if err := s3client.AddPartSizes(cmd.Bucket, uploads); err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => method call s3svc.Client.AddPartSizes failed\n")
+	}
+
+	size, err := printUploads(os.Stdout, uploads)
+	if err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "\nDry run, nothing will be aborted in s3://%s\n", cmd.Bucket)
+		return nil
+	}
+
+	details := []string{}
+	if len(cmd.Prefixes) > 0 {
+		details = append(details, "Prefixes: "+strings.Join(cmd.Prefixes, ", "))
+	}
+	details = append(details, fmt.Sprintf("Uploads:  %d multipart uploads holding %d bytes", len(uploads), size))
+
+	if err := confirmDestructive(cmd.Yes, cmd.Bucket, region, details...); err != nil {
+		return err
+	}
+
+	result := s3client.AbortUploads(cmd.Bucket, uploads)
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Aborted %d multipart uploads\n", len(result.Aborted))
+
+	if len(result.Failed) > 0 {
+		printAbortFailures(os.Stderr, result.Failed)
+		return errors.Errorf("Package: commands => func: Execute => failed to abort %d multipart uploads in s3://%s\n", len(result.Failed), cmd.Bucket)
+	}
+
+	return nil
+}
+
+// printUploads writes a table of multipart uploads and their parts, and returns the total size of the parts.
+func printUploads(w io.Writer, uploads []*s3svc.Upload) (int64, error) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	// nolint [:gas]
+	fmt.Fprintln(tw, "KEY\tUPLOAD ID\tINITIATED\tPARTS\tBYTES\t")
+
+	var parts, size int64
+	for _, upload := range uploads {
+		parts += int64(upload.Parts)
+		size += upload.Size
+
+		// nolint [:gas]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t\n", upload.Key, upload.UploadID, upload.Initiated.Format(time.RFC3339), upload.Parts, upload.Size)
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(tw, "TOTAL\t%d uploads\t\t%d\t%d\t\n", len(uploads), parts, size)
+
+	if err := tw.Flush(); err != nil {
+		return 0, errors.Wrap(err, "Package: commands => func: printUploads => method call tabwriter.Writer.Flush failed\n")
+	}
+
+	return size, nil
+}
+
+// printAbortFailures writes the multipart uploads that could not be aborted, with the reason.
+func printAbortFailures(w io.Writer, failures []*s3svc.AbortFailure) {
+	// nolint [:gas]
+	fmt.Fprintf(w, "Failed to abort %d multipart uploads:\n", len(failures))
+
+	for i, failure := range failures {
+		if i == maxListedFailures && !parser.GlobalOpts.Verbose {
+			// nolint [:gas]
+			fmt.Fprintf(w, "  ... and %d more, use --verbose to list them all\n", len(failures)-i)
+			break
+		}
+
+		// nolint [:gas]
+		fmt.Fprintf(w, "  %s (upload %s): %s\n", failure.Upload.Key, failure.Upload.UploadID, strings.TrimSpace(errors.Cause(failure.Err).Error()))
+	}
+}
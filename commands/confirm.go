@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/prompt"
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+)
+
+// confirmDestructive shows which bucket, region, and account a destructive command is about to change,
+// followed by any details, then asks the user to type the bucket name to go ahead. With yes the
+// question is skipped for automation, which is also the only way to run without a terminal on stdin.
+// An empty region stands for the region of the session.
+func confirmDestructive(yes bool, bucket, region string, details ...string) error {
+	if err := checkInteractive(yes); err != nil {
+		return err
+	}
+
+	if region == "" {
+		region = awssdk.StringValue(aws.Client.GetSession().Config.Region)
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stderr, "Bucket:   s3://%s\nRegion:   %s\nAccount:  %s\n", bucket, region, callerAccount())
+
+	for _, detail := range details {
+		// nolint [:gas]
+		fmt.Fprintln(os.Stderr, detail)
+	}
+
+	if yes {
+		return nil
+	}
+
+	ok, err := prompt.Confirm(os.Stdin, os.Stderr, "\nThis cannot be undone. Type the bucket name to continue: ", bucket)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: confirmDestructive => func call prompt.Confirm failed\n")
+	}
+
+	if !ok {
+		return errors.New("Package: commands => func: confirmDestructive => aborted, the bucket name did not match\n")
+	}
+
+	return nil
+}
+
+// checkInteractive refuses to go on when confirmation is needed but stdin is not a terminal, so that
+// commands can fail before doing any expensive work to describe what they are about to do.
+func checkInteractive(yes bool) error {
+	if !yes && !prompt.IsTerminal(os.Stdin) {
+		return errors.New("Package: commands => func: checkInteractive => stdin is not a terminal, pass --yes to run without confirmation\n")
+	}
+
+	return nil
+}
+
+// confirmBuckets shows the buckets a destructive command is about to change with their regions, and
+// the account, followed by any details, then asks the user to type the number of buckets to go ahead.
+func confirmBuckets(yes bool, buckets, regions []string, details ...string) error {
+	if err := checkInteractive(yes); err != nil {
+		return err
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stderr, "Account:  %s\nBuckets:  %d\n", callerAccount(), len(buckets))
+
+	for i, bucket := range buckets {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stderr, "  s3://%s (%s)\n", bucket, regions[i])
+	}
+
+	for _, detail := range details {
+		// nolint [:gas]
+		fmt.Fprintln(os.Stderr, detail)
+	}
+
+	if yes {
+		return nil
+	}
+
+	ok, err := prompt.Confirm(os.Stdin, os.Stderr, "\nThis cannot be undone. Type the number of buckets to continue: ", strconv.Itoa(len(buckets)))
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: confirmBuckets => func call prompt.Confirm failed\n")
+	}
+
+	if !ok {
+		return errors.New("Package: commands => func: confirmBuckets => aborted, the number of buckets did not match\n")
+	}
+
+	return nil
+}
+
+// callerAccount describes the account and identity requests are made with, for confirmations.
+func callerAccount() string {
+	identity, err := aws.Client.GetCallerIdentity()
+	if err != nil {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%s (%s)", awssdk.StringValue(identity.Account), awssdk.StringValue(identity.Arn))
+}
@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/GetTerminus/s3helper/lib/audit"
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/inventory"
+	"github.com/GetTerminus/s3helper/lib/keylist"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// DeleteKeysCommand represents the options that can be passed to the delete-keys subcommand.
+type DeleteKeysCommand struct {
+	Bucket      string  `short:"b" long:"bucket" value-name:"bucket" description:"the bucket to delete the keys from" required:"true"`
+	File        string  `short:"f" long:"file" value-name:"path" description:"read the keys from this file, - for stdin, or with --format inventory the manifest.json of the report, as s3://bucket/key or a local path" required:"false" default:"-"`
+	Format      string  `long:"format" value-name:"format" description:"how the keys are listed: a key per line with an optional tab and version id, csv, jsonl, or an S3 Inventory report of all versions, auto goes by the file name" required:"false" default:"auto" choice:"auto" choice:"plain" choice:"csv" choice:"jsonl" choice:"inventory"`
+	DryRun      bool    `long:"dry-run" description:"read and check the list and count what would be deleted without deleting anything" required:"false"`
+	Concurrency int     `short:"c" long:"concurrency" value-name:"n" description:"number of DeleteObjects requests to run in parallel" required:"false" default:"4"`
+	MaxRPS      float64 `long:"max-rps" value-name:"n" description:"send at most this many requests per second, concurrency also drops by itself while S3 answers with SlowDown" required:"false"`
+	AuditLog    string  `long:"audit-log" value-name:"path" description:"append a JSON line for every key deleted or failed to this file, as the run goes" required:"false"`
+	Yes         bool    `short:"y" long:"yes" description:"do not ask for confirmation, required when the keys come from stdin or stdin is not a terminal" required:"false"`
+}
+
+func init() {
+	var cmd DeleteKeysCommand
+
+	// nolint [:errcheck]
+	parser.OptParser.AddCommand(
+		"delete-keys",
+		"Delete a list of keys from an s3 bucket",
+		"Delete the keys, or particular versions of them, listed in a file or on stdin from an s3 bucket, 1000 per request, and report what became of each",
+		&cmd,
+	)
+}
+
+// Execute implements the interface for the go-flags subcommand.
+func (cmd *DeleteKeysCommand) Execute(args []string) error {
+	if cmd.AuditLog != "" && cmd.DryRun {
+		return errors.New("Package: commands => func: Execute => --audit-log records deletions and cannot be used with --dry-run\n")
+	}
+
+	if !cmd.DryRun {
+		// the list may be what's on stdin, so there'd be nothing left to read an answer from
+		if cmd.File == "-" && !cmd.Yes {
+			return errors.New("Package: commands => func: Execute => the keys are read from stdin, pass --yes to delete them without confirmation\n")
+		}
+
+		if err := checkInteractive(cmd.Yes); err != nil {
+			return err
+		}
+	}
+
+	if !cmd.DryRun {
+		if err := cmd.checkFile(); err != nil {
+			return err
+		}
+	}
+
+	keys, versions := 0, 0
+	if cmd.File != "-" || cmd.DryRun {
+		// a first pass checks the whole list before anything is deleted, stdin can only be read once
+		var err error
+		if keys, versions, err = cmd.count(); err != nil {
+			return err
+		}
+	}
+
+	if cmd.DryRun {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "Dry run, nothing will be deleted from s3://%s\n%d keys and %d versions would be deleted\n", cmd.Bucket, keys, versions)
+		return nil
+	}
+
+	region := bucketRegion(cmd.Bucket)
+
+	if cmd.File != "-" {
+		details := fmt.Sprintf("Keys:     %d keys and %d versions listed in %s", keys, versions, cmd.File)
+		if err := confirmDestructive(cmd.Yes, cmd.Bucket, region, details); err != nil {
+			return err
+		}
+	}
+
+	var auditLog *audit.Log
+	if cmd.AuditLog != "" {
+		var err error
+		auditLog, err = audit.Open(cmd.AuditLog)
+		if err != nil {
+			return errors.Wrap(err, "Package: commands => func: Execute => func call audit.Open failed\n")
+		}
+
+		// nolint [:errcheck]
+		defer auditLog.Close()
+	}
+
+	report := newDeleteReport(cmd.Bucket, auditLog)
+	report.listAll = true
+
+	s3client := s3svc.NewClient(
+		s3.New(aws.Client.GetRegionSession(region)),
+		parser.GlobalOpts.Verbose,
+		s3svc.WithConcurrency(cmd.Concurrency),
+		s3svc.WithMaxRPS(cmd.MaxRPS),
+		s3svc.WithOutcomes(report.add),
+	)
+
+	list, source, err := cmd.open()
+	if err != nil {
+		return err
+	}
+
+	// nolint [:errcheck]
+	defer list.Close()
+
+	// every key is listed on stdout as it's deleted, so progress is logged rather than redrawn
+	display := newLogDisplay()
+	display.Start(clientCounts(s3client))
+	totals, err := s3client.DeleteVersions(cmd.Bucket, source)
+	display.Stop()
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => method call s3svc.Client.DeleteVersions failed\n")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Deleted %d keys and versions\n", totals.Deleted)
+
+	if totals.Failed > 0 {
+		report.printFailures(os.Stderr)
+		return errors.Errorf("Package: commands => func: Execute => failed to delete %d keys and versions from s3://%s\n", totals.Failed, cmd.Bucket)
+	}
+
+	return nil
+}
+
+// checkFile makes sure a --file other than stdin can be read twice, once to check and count the list
+// and once to delete what it lists, a dry run only reads it once. A pipe or FIFO would be empty the second time, deleting nothing.
+func (cmd *DeleteKeysCommand) checkFile() error {
+	if cmd.File == "-" || strings.HasPrefix(cmd.File, "s3://") {
+		return nil
+	}
+
+	info, err := os.Stat(cmd.File)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: checkFile => func call os.Stat failed\n")
+	}
+
+	if !info.Mode().IsRegular() {
+		return errors.Errorf("Package: commands => func: checkFile => %s is not a regular file and can only be read once, pipe it to stdin with --file - instead\n", cmd.File)
+	}
+
+	return nil
+}
+
+// format returns the --format of the list, going by the file name for auto: a manifest.json is that
+// of an inventory report, anything else goes by its extension.
+func (cmd *DeleteKeysCommand) format() string {
+	if cmd.Format != "auto" {
+		return cmd.Format
+	}
+
+	if path.Base(cmd.File) == "manifest.json" {
+		return "inventory"
+	}
+
+	return string(keylist.FormatFor(cmd.File))
+}
+
+// open opens the list of keys and returns the versions it lists.
+func (cmd *DeleteKeysCommand) open() (io.Closer, s3svc.VersionSource, error) {
+	if cmd.format() == "inventory" {
+		source, err := cmd.openInventory()
+		return ioutil.NopCloser(nil), source, err
+	}
+
+	var list io.ReadCloser = ioutil.NopCloser(os.Stdin)
+	if cmd.File != "-" {
+		f, err := os.Open(cmd.File)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Package: commands => func: open => func call os.Open failed\n")
+		}
+		list = f
+	}
+
+	reader, err := keylist.NewReader(list, keylist.Format(cmd.format()))
+	if err != nil {
+		// nolint [:errcheck]
+		list.Close()
+		return nil, nil, errors.Wrap(err, "Package: commands => func: open => func call keylist.NewReader failed\n")
+	}
+
+	return list, &keySource{reader: reader}, nil
+}
+
+// openInventory opens the inventory report --file is the manifest of, which must list every version
+// of the bucket, so that each row names the version to delete.
+func (cmd *DeleteKeysCommand) openInventory() (s3svc.VersionSource, error) {
+	if cmd.File == "-" {
+		return nil, errors.New("Package: commands => func: openInventory => --format inventory reads the manifest.json given with --file, not stdin\n")
+	}
+
+	manifest, open, err := openInventory(cmd.File)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.SourceBucket != cmd.Bucket {
+		return nil, errors.Errorf("Package: commands => func: openInventory => %s is a report of s3://%s, not s3://%s\n", cmd.File, manifest.SourceBucket, cmd.Bucket)
+	}
+
+	if err := manifest.CheckSchema(inventory.FieldVersionID); err != nil {
+		return nil, errors.Wrap(err, "Package: commands => func: openInventory => the report must list all versions\n")
+	}
+
+	return inventory.NewReader(manifest, open), nil
+}
+
+// count reads the whole list, and returns how many keys it has without and with a version ID.
+func (cmd *DeleteKeysCommand) count() (int, int, error) {
+	list, source, err := cmd.open()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// nolint [:errcheck]
+	defer list.Close()
+
+	keys, versions := 0, 0
+	for source.Next() {
+		if source.Version().VersionID == "" {
+			keys++
+		} else {
+			versions++
+		}
+	}
+
+	if err := source.Err(); err != nil {
+		return 0, 0, errors.Wrap(err, "Package: commands => func: count => method call s3svc.VersionSource.Next failed\n")
+	}
+
+	return keys, versions, nil
+}
+
+// keySource yields the entries of a list of keys as versions to delete.
+type keySource struct {
+	reader  *keylist.Reader
+	version *s3svc.Version
+}
+
+func (s *keySource) Next() bool {
+	if !s.reader.Next() {
+		return false
+	}
+
+	entry := s.reader.Entry()
+	s.version = &s3svc.Version{Key: entry.Key, VersionID: entry.VersionID}
+
+	return true
+}
+
+func (s *keySource) Version() *s3svc.Version {
+	return s.version
+}
+
+func (s *keySource) Err() error {
+	return s.reader.Err()
+}
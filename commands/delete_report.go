@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/GetTerminus/s3helper/lib/audit"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/parser"
+)
+
+// maxListedFailures caps how many individual keys the failure summaries list, the per-code counts cover the rest.
+const maxListedFailures = 20
+
+// deleteReport takes the outcomes DeleteVersions streams back batch by batch. With --verbose, or
+// listAll, it lists every version as its batch finishes, it records them in the audit log when there
+// is one, and it keeps what's needed to summarize the failures at the end, without holding on to
+// every version.
+type deleteReport struct {
+	bucket   string
+	auditLog *audit.Log
+	listAll  bool
+
+	failed   int
+	byCode   map[string]int
+	failures []*s3svc.DeleteFailure
+}
+
+// newDeleteReport returns a report for deletions from bucket, auditLog may be nil.
+func newDeleteReport(bucket string, auditLog *audit.Log) *deleteReport {
+	return &deleteReport{bucket: bucket, auditLog: auditLog, listAll: parser.GlobalOpts.Verbose, byCode: map[string]int{}}
+}
+
+// add is the outcome function for s3svc.WithOutcomes.
+func (r *deleteReport) add(outcomes []s3svc.Outcome) error {
+	for _, outcome := range outcomes {
+		if r.listAll {
+			printOutcome(os.Stdout, r.bucket, outcome)
+		}
+
+		if outcome.Failure == nil {
+			continue
+		}
+
+		r.failed++
+		r.byCode[outcome.Failure.Code]++
+		if len(r.failures) < maxListedFailures {
+			r.failures = append(r.failures, outcome.Failure)
+		}
+	}
+
+	if r.auditLog == nil {
+		return nil
+	}
+
+	return r.auditLog.Write(auditRecords(r.bucket, outcomes)...)
+}
+
+// printFailures writes a summary of keys that could not be deleted, grouped by error code.
+func (r *deleteReport) printFailures(w io.Writer) {
+	codes := make([]string, 0, len(r.byCode))
+	for code := range r.byCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	// nolint [:gas]
+	fmt.Fprintf(w, "Failed to delete %d versions and delete markers:\n", r.failed)
+
+	for _, code := range codes {
+		// nolint [:gas]
+		fmt.Fprintf(w, "  %s: %d\n", code, r.byCode[code])
+	}
+
+	for _, failure := range r.failures {
+		// nolint [:gas]
+		fmt.Fprintf(w, "  %s (version %s): %s %s\n", failure.Key, failure.VersionID, failure.Code, failure.Message)
+	}
+
+	if more := r.failed - len(r.failures); more > 0 {
+		hint := "use --verbose to list them all"
+		if r.listAll {
+			hint = "listed above"
+		}
+
+		// nolint [:gas]
+		fmt.Fprintf(w, "  ... and %d more, %s\n", more, hint)
+	}
+}
+
+// printOutcome writes a single line saying what became of a version in bucket.
+func printOutcome(w io.Writer, bucket string, outcome s3svc.Outcome) {
+	kind := "version " + outcome.Version.VersionID
+	if outcome.Version.IsDeleteMarker {
+		kind = "delete marker " + outcome.Version.VersionID
+	}
+
+	// a key given without a version ID was deleted as a whole, leaving a delete marker if versioned
+	if outcome.Version.VersionID == "" {
+		kind = "key"
+	}
+
+	if outcome.Failure != nil {
+		// nolint [:gas]
+		fmt.Fprintf(w, "failed   s3://%s/%s (%s): %s %s\n", bucket, outcome.Version.Key, kind, outcome.Failure.Code, outcome.Failure.Message)
+		return
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(w, "deleted  s3://%s/%s (%s)\n", bucket, outcome.Version.Key, kind)
+}
+
+// auditRecords turns the outcomes of a batch into audit log records.
+func auditRecords(bucket string, outcomes []s3svc.Outcome) []audit.Record {
+	records := make([]audit.Record, len(outcomes))
+	for i, outcome := range outcomes {
+		records[i] = audit.Record{
+			Bucket:       bucket,
+			Key:          outcome.Version.Key,
+			VersionID:    outcome.Version.VersionID,
+			DeleteMarker: outcome.Version.IsDeleteMarker,
+			Size:         outcome.Version.Size,
+			LastModified: outcome.Version.LastModified,
+			Result:       audit.ResultDeleted,
+		}
+
+		if outcome.Failure != nil {
+			records[i].Result = audit.ResultFailed
+			records[i].Code = outcome.Failure.Code
+			records[i].Message = outcome.Failure.Message
+		}
+	}
+
+	return records
+}
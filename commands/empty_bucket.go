@@ -1,19 +1,61 @@
 package commands
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/GetTerminus/s3helper/lib/audit"
 	"github.com/GetTerminus/s3helper/lib/aws"
 	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/checkpoint"
+	"github.com/GetTerminus/s3helper/lib/inventory"
+	"github.com/GetTerminus/s3helper/lib/keymatch"
 	"github.com/GetTerminus/s3helper/lib/parser"
+	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/pkg/errors"
 )
 
 // EmptyBucketCommand represents the options that can be passed to the empty-bucket subcommand.
 type EmptyBucketCommand struct {
-	Bucket string `short:"b" long:"bucket" value-name:"bucket" description:"the bucket to empty" required:"true"`
+	Buckets           []string      `short:"b" long:"bucket" value-name:"bucket" description:"the bucket to empty, may be repeated" required:"false"`
+	BucketPattern     string        `long:"bucket-pattern" value-name:"glob" description:"empty every bucket whose name matches this glob, like ci-preview-pr-*" required:"false"`
+	BucketConcurrency int           `long:"bucket-concurrency" value-name:"n" description:"with several buckets, how many to empty at the same time" required:"false" default:"4"`
+	Prefixes          []string      `long:"prefix" value-name:"prefix" description:"only delete keys under this prefix, may be repeated" required:"false"`
+	Inventory         string        `long:"inventory" value-name:"location" description:"take the versions to delete from the manifest.json of an S3 Inventory report, as s3://bucket/key or a local path, instead of listing the bucket" required:"false"`
+	DryRun            bool          `long:"dry-run" description:"list what would be deleted and print a plan without deleting anything" required:"false"`
+	PlanFile          string        `long:"plan-file" value-name:"path" description:"with --dry-run, write every key and version id that would be deleted to this file" required:"false"`
+	Concurrency       int           `short:"c" long:"concurrency" value-name:"n" description:"number of DeleteObjects requests to run in parallel" required:"false" default:"4"`
+	MaxRPS            float64       `long:"max-rps" value-name:"n" description:"send at most this many requests per second, concurrency also drops by itself while S3 answers with SlowDown" required:"false"`
+	Only              string        `long:"only" value-name:"kind" description:"only delete noncurrent versions, stale delete markers that no longer hide a version, or current versions" required:"false" choice:"noncurrent" choice:"delete-markers" choice:"current"`
+	OlderThan         parser.Cutoff `long:"older-than" value-name:"age" description:"only delete versions last modified before this age (90d, 2w, 36h) or RFC3339 timestamp" required:"false"`
+	SinceNoncurrent   bool          `long:"since-noncurrent" description:"with --older-than, measure noncurrent versions from when they were replaced" required:"false"`
+	Includes          []string      `long:"include" value-name:"pattern" description:"only delete keys matching this glob, or regular expression when prefixed with re:, may be repeated" required:"false"`
+	Excludes          []string      `long:"exclude" value-name:"pattern" description:"never delete keys matching this glob, or regular expression when prefixed with re:, may be repeated" required:"false"`
+	Checkpoint        string        `long:"checkpoint" value-name:"path" description:"save progress to this file as the run goes, removed once everything was deleted" required:"false"`
+	Resume            bool          `long:"resume" description:"continue the run saved in the --checkpoint file instead of starting over" required:"false"`
+	AuditLog          string        `long:"audit-log" value-name:"path" description:"append a JSON line for every version deleted or failed to this file, as the run goes" required:"false"`
+	BypassGovernance  bool          `long:"bypass-governance" description:"delete versions under governance-mode Object Lock retention, needs the s3:BypassGovernanceRetention permission" required:"false"`
+	MFASerial         string        `long:"mfa-serial" value-name:"arn" description:"serial number or ARN of the MFA device, for buckets with MFA Delete, asks for a code as the run goes" required:"false"`
+	ViaLifecycle      bool          `long:"via-lifecycle" description:"instead of deleting, install lifecycle rules that have S3 expire everything within a day or two, next to the rules of the bucket with --prefix and in place of them without" required:"false"`
+	LifecycleBackup   string        `long:"lifecycle-backup" value-name:"path" description:"file the previous lifecycle configuration is saved to with --via-lifecycle and read from with --restore-lifecycle (default: <bucket>.lifecycle-backup.json)" required:"false"`
+	RestoreLifecycle  bool          `long:"restore-lifecycle" description:"put back the lifecycle configuration --via-lifecycle replaced, from the --lifecycle-backup file" required:"false"`
+	DeleteBucket      bool          `long:"delete-bucket" description:"after emptying, abort all multipart uploads, check the bucket is empty, and delete it" required:"false"`
+	Yes               bool          `short:"y" long:"yes" description:"do not ask for confirmation, required when stdin is not a terminal" required:"false"`
+
+	// bucket is the one bucket a run works on, of those given with Buckets or matching BucketPattern,
+	// and region where it lives
+	bucket string
+	region string
+
+	// locks skips versions Object Lock keeps from being deleted, set by checkLocks
+	locks s3svc.Filter
 }
 
 func init() {
@@ -30,22 +72,634 @@ func init() {
 
 // Execute implements the interface for the go-flags subcommand.
 func (cmd *EmptyBucketCommand) Execute(args []string) error {
+	if cmd.DeleteBucket && (len(cmd.Prefixes) > 0 || cmd.filtered()) {
+		return errors.New("Package: commands => func: Execute => --delete-bucket empties the whole bucket and cannot be combined with --prefix, --only, --older-than, --include or --exclude\n")
+	}
+
+	if cmd.PlanFile != "" && !cmd.DryRun {
+		return errors.New("Package: commands => func: Execute => --plan-file can only be used with --dry-run\n")
+	}
+
+	if cmd.AuditLog != "" && cmd.DryRun {
+		return errors.New("Package: commands => func: Execute => --audit-log records deletions and cannot be used with --dry-run\n")
+	}
+
+	if err := cmd.checkLifecycle(); err != nil {
+		return err
+	}
+
+	if err := cmd.checkInventory(); err != nil {
+		return err
+	}
+
+	buckets, err := cmd.buckets()
+	if err != nil {
+		return err
+	}
+
+	if len(buckets) > 1 {
+		if cmd.ViaLifecycle || cmd.RestoreLifecycle || cmd.Inventory != "" {
+			return errors.New("Package: commands => func: Execute => --via-lifecycle, --restore-lifecycle and --inventory work on a single bucket\n")
+		}
+
+		return cmd.emptyBuckets(buckets)
+	}
+
+	cmd.bucket = buckets[0]
+	cmd.region = bucketRegion(cmd.bucket)
+
+	if cmd.RestoreLifecycle {
+		return cmd.restoreLifecycle(cmd.newClient())
+	}
+
+	if cmd.ViaLifecycle {
+		if !cmd.DryRun {
+			if err := checkInteractive(cmd.Yes); err != nil {
+				return err
+			}
+		}
+
+		return cmd.viaLifecycle(cmd.newClient())
+	}
+
+	cp, err := cmd.checkpoint()
+	if err != nil {
+		return err
+	}
+
+	options := []func(*s3svc.Client){s3svc.WithConcurrency(cmd.Concurrency), s3svc.WithMaxRPS(cmd.MaxRPS)}
+	if cmd.Checkpoint != "" {
+		options = append(options, s3svc.WithProgress(cmd.saveProgress(cp)))
+	}
+
+	var auditLog *audit.Log
+	if cmd.AuditLog != "" {
+		auditLog, err = audit.Open(cmd.AuditLog)
+		if err != nil {
+			return errors.Wrap(err, "Package: commands => func: Execute => func call audit.Open failed\n")
+		}
+
+		// nolint [:errcheck]
+		defer auditLog.Close()
+	}
+
+	report := newDeleteReport(cmd.bucket, auditLog)
+	display := newDisplay()
+	options = append(options, s3svc.WithOutcomes(report.add))
+
+	if cmd.MFASerial != "" && !cmd.DryRun {
+		option, err := mfaOption(cmd.MFASerial, display)
+		if err != nil {
+			return err
+		}
+		options = append(options, option)
+	}
+
+	s3client := cmd.newClient(options...)
+
+	if !cmd.DryRun {
+		if err := checkInteractive(cmd.Yes); err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.checkLocks(s3client, cp.Marker); err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		return cmd.plan(s3client)
+	}
+
+	source, err := cmd.source(s3client, cp.Marker)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.confirm(s3client, cp.Marker); err != nil {
+		return err
+	}
+
+	if cp.Marker.Key != "" {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "Resuming after %s (version %s), %d versions and delete markers were deleted before\n", cp.Marker.Key, cp.Marker.VersionID, cp.Counters["deleted"])
+	}
+
+	if len(cmd.Prefixes) == 0 {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "Deleting contents of s3://%s\n", cmd.bucket)
+	}
+
+	for _, prefix := range cmd.Prefixes {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "Deleting contents of s3://%s/%s\n", cmd.bucket, prefix)
+	}
+
+	display.Start(clientCounts(s3client))
+	totals, err := s3client.DeleteVersions(cmd.bucket, source)
+	display.Stop()
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => method call s3svc.Client.DeleteVersions failed\n")
+	}
 
 	// nolint [:gas]
-	fmt.Fprintf(os.Stdout, "Deleting contents of s3://%s\n", cmd.Bucket)
+	fmt.Fprintf(os.Stdout, "Deleted %d versions (%d bytes) and %d delete markers\n", totals.Deleted-totals.DeleteMarkers, totals.Bytes, totals.DeleteMarkers)
+
+	if totals.Failed > 0 {
+		report.printFailures(os.Stderr)
+		return errors.Errorf("Package: commands => func: Execute => failed to delete %d versions and delete markers from s3://%s\n", totals.Failed, cmd.bucket)
+	}
+
+	if cmd.DeleteBucket {
+		if err := cmd.deleteBucket(s3client); err != nil {
+			return err
+		}
+	}
+
+	if cmd.Checkpoint != "" {
+		if err := checkpoint.Remove(cmd.Checkpoint); err != nil {
+			return errors.Wrap(err, "Package: commands => func: Execute => func call checkpoint.Remove failed\n")
+		}
+	}
 
-	awsSession := aws.Client.GetSession()
-	s3client := s3svc.NewClient(s3.New(awsSession), parser.GlobalOpts.Verbose)
+	return nil
+}
 
-	resp, err := s3client.DeleteBucketContents(cmd.Bucket)
+// checkLifecycle refuses options that don't go with --via-lifecycle or --restore-lifecycle. Lifecycle
+// rules expire whole prefixes, with no way to pick versions or to record them one by one.
+func (cmd *EmptyBucketCommand) checkLifecycle() error {
+	if cmd.ViaLifecycle && cmd.RestoreLifecycle {
+		return errors.New("Package: commands => func: checkLifecycle => --via-lifecycle and --restore-lifecycle cannot be combined\n")
+	}
+
+	if cmd.LifecycleBackup != "" && !cmd.ViaLifecycle && !cmd.RestoreLifecycle {
+		return errors.New("Package: commands => func: checkLifecycle => --lifecycle-backup can only be used with --via-lifecycle or --restore-lifecycle\n")
+	}
+
+	if !cmd.ViaLifecycle {
+		return nil
+	}
+
+	if cmd.filtered() || cmd.DeleteBucket || cmd.PlanFile != "" || cmd.Checkpoint != "" || cmd.AuditLog != "" || cmd.MFASerial != "" {
+		return errors.New("Package: commands => func: checkLifecycle => --via-lifecycle expires everything under the prefixes and cannot be combined with --only, --older-than, --include, --exclude, --plan-file, --checkpoint, --audit-log, --mfa-serial or --delete-bucket\n")
+	}
+
+	return nil
+}
+
+// checkInventory refuses options that don't go with --inventory. Rows of a report are not in listing
+// order, so there is no position to resume from and the versions of a key don't come together, and
+// reports don't say when a version was replaced.
+func (cmd *EmptyBucketCommand) checkInventory() error {
+	if cmd.Inventory == "" {
+		return nil
+	}
+
+	if cmd.Checkpoint != "" || cmd.Resume || cmd.Only == "delete-markers" || cmd.SinceNoncurrent || cmd.ViaLifecycle {
+		return errors.New("Package: commands => func: checkInventory => --inventory cannot be combined with --checkpoint, --resume, --only delete-markers, --since-noncurrent or --via-lifecycle\n")
+	}
+
+	return nil
+}
+
+// newClient returns a client for the bucket's region that can look up Object Lock, and bypasses
+// governance-mode retention with --bypass-governance.
+func (cmd *EmptyBucketCommand) newClient(options ...func(*s3svc.Client)) *s3svc.Client {
+	svc := s3.New(aws.Client.GetRegionSession(cmd.region))
+	if cmd.BypassGovernance {
+		s3svc.BypassGovernanceRetention(svc)
+	}
+	options = append(options, s3svc.WithLockAPI(s3svc.NewLockAPI(svc)))
+
+	return s3svc.NewClient(svc, parser.GlobalOpts.Verbose, options...)
+}
+
+// buckets returns the buckets given with --bucket followed by those matching --bucket-pattern, each
+// only once.
+func (cmd *EmptyBucketCommand) buckets() ([]string, error) {
+	if len(cmd.Buckets) == 0 && cmd.BucketPattern == "" {
+		return nil, errors.New("Package: commands => func: buckets => --bucket or --bucket-pattern is required\n")
+	}
+
+	buckets := append([]string{}, cmd.Buckets...)
+
+	if cmd.BucketPattern != "" {
+		s3client := s3svc.NewClient(s3.New(aws.Client.GetSession()), parser.GlobalOpts.Verbose)
+
+		matched, err := s3client.ListBuckets(cmd.BucketPattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "Package: commands => func: buckets => method call s3svc.Client.ListBuckets failed\n")
+		}
+
+		if len(matched) == 0 && len(buckets) == 0 {
+			return nil, errors.Errorf("Package: commands => func: buckets => no bucket matches %q\n", cmd.BucketPattern)
+		}
+
+		buckets = append(buckets, matched...)
+	}
+
+	seen := map[string]bool{}
+	unique := buckets[:0]
+	for _, bucket := range buckets {
+		if !seen[bucket] {
+			seen[bucket] = true
+			unique = append(unique, bucket)
+		}
+	}
+
+	return unique, nil
+}
+
+// checkLocks looks for an Object Lock configuration on the bucket. When there is one, it reports how
+// many of the versions the command selects are locked, and makes source skip those that can't be
+// deleted so they are not tried again on every run.
+func (cmd *EmptyBucketCommand) checkLocks(s3client *s3svc.Client, after checkpoint.Marker) error {
+	config, err := s3client.LockConfiguration(cmd.bucket)
 	if err != nil {
-		return errors.Wrap(err, "Package: commands => func: Execute => method call s3svc.Client.DeleteBucketContents failed\n")
+		// not being allowed to read the configuration shouldn't keep anyone from emptying a bucket
+		// they were able to empty before, locked versions then simply fail to delete
+
+		// nolint [:gas]
+		fmt.Fprintf(os.Stderr, "Could not check s3://%s for Object Lock, carrying on without: %s\n", cmd.bucket, strings.TrimSpace(errors.Cause(err).Error()))
+		return nil
 	}
 
-	if parser.GlobalOpts.Verbose {
+	if config == nil {
+		return nil
+	}
+
+	retention := "without default retention"
+	if config.Rule != nil && config.Rule.DefaultRetention != nil {
+		d := config.Rule.DefaultRetention
+		if d.Years != nil {
+			retention = fmt.Sprintf("with default %s retention for %d years", awssdk.StringValue(d.Mode), awssdk.Int64Value(d.Years))
+		} else {
+			retention = fmt.Sprintf("with default %s retention for %d days", awssdk.StringValue(d.Mode), awssdk.Int64Value(d.Days))
+		}
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Object Lock is enabled on s3://%s %s, checking every version\n", cmd.bucket, retention)
+
+	source, err := cmd.source(s3client, after)
+	if err != nil {
+		return err
+	}
+
+	report, err := s3client.ScanLocks(cmd.bucket, source)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: checkLocks => method call s3svc.Client.ScanLocks failed\n")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "  %d versions under governance-mode retention, until %s at the latest\n", report.Governance.Versions, formatUntil(report.Governance.Until))
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "  %d versions under compliance-mode retention, until %s at the latest\n", report.Compliance.Versions, formatUntil(report.Compliance.Until))
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "  %d versions under legal hold\n", report.LegalHold)
+
+	skipped := report.Undeletable(cmd.BypassGovernance)
+	if skipped == 0 {
+		return nil
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Skipping %d locked versions that cannot be deleted\n", skipped)
+
+	if report.Governance.Versions > 0 && !cmd.BypassGovernance {
+		// nolint [:gas]
+		fmt.Fprintln(os.Stdout, "Pass --bypass-governance to delete the versions under governance-mode retention")
+	}
+
+	if cmd.DeleteBucket {
+		return errors.Errorf("Package: commands => func: checkLocks => s3://%s would not be empty with %d locked versions left, not deleting anything\n", cmd.bucket, skipped)
+	}
+
+	cmd.locks = report.Deletable(cmd.BypassGovernance)
+	return nil
+}
+
+// formatUntil formats the end of a retention period, which is the zero time when nothing is retained.
+func formatUntil(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// checkpoint loads the checkpoint to resume from with --resume, or starts a new one.
+func (cmd *EmptyBucketCommand) checkpoint() (*checkpoint.Checkpoint, error) {
+	if !cmd.Resume {
+		return checkpoint.New("empty-bucket", cmd.bucket, cmd.options()), nil
+	}
+
+	if cmd.Checkpoint == "" {
+		return nil, errors.New("Package: commands => func: checkpoint => --resume needs the --checkpoint file to resume from\n")
+	}
+
+	cp, err := checkpoint.Load(cmd.Checkpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "Package: commands => func: checkpoint => func call checkpoint.Load failed\n")
+	}
+
+	if err := cp.Match("empty-bucket", cmd.bucket, cmd.options()); err != nil {
+		return nil, errors.Wrap(err, "Package: commands => func: checkpoint => the checkpoint is for another run\n")
+	}
+
+	return cp, nil
+}
+
+// options describes the flags that select what gets deleted, for comparing a checkpoint with the
+// current run. An --older-than age is measured from the time of each run, so it's left out.
+func (cmd *EmptyBucketCommand) options() string {
+	return fmt.Sprintf("prefix=%s only=%s since-noncurrent=%t include=%s exclude=%s",
+		strings.Join(s3svc.NormalizePrefixes(cmd.Prefixes), ","),
+		cmd.Only,
+		cmd.SinceNoncurrent,
+		strings.Join(cmd.Includes, ","),
+		strings.Join(cmd.Excludes, ","),
+	)
+}
+
+// saveProgress returns a progress function that adds to the counters the checkpoint started with
+// and saves it after every batch.
+func (cmd *EmptyBucketCommand) saveProgress(cp *checkpoint.Checkpoint) func(s3svc.Progress) {
+	deleted, failed := cp.Counters["deleted"], cp.Counters["failed"]
+	warned := false
+
+	return func(p s3svc.Progress) {
+		cp.Marker = checkpoint.Marker{Key: p.Key, VersionID: p.VersionID}
+		cp.Counters["deleted"] = deleted + int64(p.Deleted)
+		cp.Counters["failed"] = failed + int64(p.Failed)
+
+		// losing the checkpoint only costs re-listing on resume, not worth stopping a long run for
+		if err := cp.Save(cmd.Checkpoint); err != nil && !warned {
+			warned = true
+
+			// nolint [:gas]
+			fmt.Fprintf(os.Stderr, "Could not save the checkpoint, carrying on without it: %s\n", strings.TrimSpace(err.Error()))
+		}
+	}
+}
+
+// deleteBucket aborts the multipart uploads left in the emptied bucket, checks nothing was written
+// in the meantime, and deletes the bucket.
+func (cmd *EmptyBucketCommand) deleteBucket(s3client *s3svc.Client) error {
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Aborting multipart uploads in s3://%s\n", cmd.bucket)
+
+	uploads, err := s3client.ListUploads(cmd.bucket)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: deleteBucket => method call s3svc.Client.ListUploads failed\n")
+	}
+
+	aborted := s3client.AbortUploads(cmd.bucket, uploads)
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Aborted %d multipart uploads\n", len(aborted.Aborted))
+
+	if len(aborted.Failed) > 0 {
+		printAbortFailures(os.Stderr, aborted.Failed)
+		return errors.Errorf("Package: commands => func: deleteBucket => failed to abort %d multipart uploads in s3://%s, not deleting the bucket\n", len(aborted.Failed), cmd.bucket)
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Checking s3://%s is empty\n", cmd.bucket)
+
+	leftovers, err := s3client.CheckEmpty(cmd.bucket)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: deleteBucket => method call s3svc.Client.CheckEmpty failed\n")
+	}
+
+	if !leftovers.Empty() {
+		return errors.Errorf("Package: commands => func: deleteBucket => s3://%s still holds %d versions and delete markers and %d multipart uploads, something is still writing to it, not deleting the bucket\n", cmd.bucket, leftovers.Versions, leftovers.Uploads)
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Deleting bucket s3://%s\n", cmd.bucket)
+
+	if err := s3client.DeleteBucket(cmd.bucket); err != nil {
+		return errors.Wrap(err, "Package: commands => func: deleteBucket => method call s3svc.Client.DeleteBucket failed\n")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Deleted bucket s3://%s\n", cmd.bucket)
+
+	return nil
+}
+
+// filtered reports whether any option narrows down which versions are deleted, beyond the prefixes.
+func (cmd *EmptyBucketCommand) filtered() bool {
+	return cmd.Only != "" || !cmd.OlderThan.IsZero() || len(cmd.Includes) > 0 || len(cmd.Excludes) > 0
+}
+
+// estimateLimit caps how many versions are listed to estimate the size of a deletion.
+const estimateLimit = 10000
+
+// confirm estimates how much the command will delete and asks the user to go ahead.
+func (cmd *EmptyBucketCommand) confirm(s3client *s3svc.Client, after checkpoint.Marker) error {
+	if err := checkInteractive(cmd.Yes); err != nil {
+		return err
+	}
+
+	it := s3client.NewVersionIterator(cmd.bucket, cmd.Prefixes...)
+	it.StartAfter(after.Key, after.VersionID)
+
+	count, more, err := s3svc.CountVersions(it, estimateLimit)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: confirm => func call s3svc.CountVersions failed\n")
+	}
+
+	estimate := fmt.Sprintf("%d versions and delete markers", count)
+	if more {
+		estimate = "more than " + estimate
+	}
+
+	if cmd.filtered() {
+		estimate += ", before filters"
+	}
+
+	return confirmDestructive(cmd.Yes, cmd.bucket, cmd.region, cmd.details("Objects:  "+estimate)...)
+}
+
+// details describes what the command deletes in every bucket for confirmations, with more lines
+// about what gets deleted inserted before what happens afterwards.
+func (cmd *EmptyBucketCommand) details(more ...string) []string {
+	details := []string{}
+	if len(cmd.Prefixes) > 0 {
+		details = append(details, "Prefixes: "+strings.Join(cmd.Prefixes, ", "))
+	}
+	details = append(details, more...)
+
+	if cmd.DeleteBucket {
+		details = append(details, "Then:     abort all multipart uploads and delete the bucket itself")
+	}
+
+	return details
+}
+
+// source lists the versions and delete markers selected by the command's options, starting right
+// after the marker when it's set.
+func (cmd *EmptyBucketCommand) source(s3client *s3svc.Client, after checkpoint.Marker) (s3svc.VersionSource, error) {
+	var source s3svc.VersionSource
+
+	if cmd.Inventory != "" {
+		reader, err := cmd.inventoryReader()
+		if err != nil {
+			return nil, err
+		}
+
+		source = s3svc.FilterVersions(reader, s3svc.UnderPrefixes(cmd.Prefixes...))
+	} else {
+		it := s3client.NewVersionIterator(cmd.bucket, cmd.Prefixes...)
+		it.StartAfter(after.Key, after.VersionID)
+		source = it
+	}
+
+	switch cmd.Only {
+	case "noncurrent":
+		source = s3svc.FilterVersions(source, s3svc.Noncurrent)
+	case "delete-markers":
+		source = s3svc.FilterKeys(source, s3svc.StaleDeleteMarkers)
+	case "current":
+		source = s3svc.FilterVersions(source, s3svc.Current)
+	}
+
+	if !cmd.OlderThan.IsZero() {
+		source = s3svc.FilterVersions(source, s3svc.OlderThan(cmd.OlderThan.Time, cmd.SinceNoncurrent))
+	}
+
+	if len(cmd.Includes) > 0 || len(cmd.Excludes) > 0 {
+		matcher, err := keymatch.New(cmd.Includes, cmd.Excludes)
+		if err != nil {
+			return nil, errors.Wrap(err, "Package: commands => func: source => func call keymatch.New failed\n")
+		}
+
+		source = s3svc.FilterVersions(source, func(v *s3svc.Version) bool { return matcher.Match(v.Key) })
+	}
+
+	if cmd.locks != nil {
+		source = s3svc.FilterVersions(source, cmd.locks)
+	}
+
+	return source, nil
+}
+
+// inventoryReader opens the --inventory report, which must be of the bucket and have the fields
+// --older-than and --only filter by, to read versions from.
+func (cmd *EmptyBucketCommand) inventoryReader() (*inventory.Reader, error) {
+	manifest, open, err := openInventory(cmd.Inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.SourceBucket != cmd.bucket {
+		return nil, errors.Errorf("Package: commands => func: inventoryReader => %s is a report of s3://%s, not s3://%s\n", cmd.Inventory, manifest.SourceBucket, cmd.bucket)
+	}
+
+	if err := manifest.CheckSchema(inventory.FilterFields(!cmd.OlderThan.IsZero(), cmd.Only != "")...); err != nil {
+		return nil, errors.Wrap(err, "Package: commands => func: inventoryReader => the report cannot be filtered with --older-than or --only\n")
+	}
+
+	if !manifest.Versioned() {
+		// nolint [:gas]
+		fmt.Fprintln(os.Stderr, "The inventory report only lists current versions, in a versioned bucket deleting them leaves delete markers and older versions behind")
+	}
+
+	return inventory.NewReader(manifest, open), nil
+}
+
+// printPlan writes a table of what a plan would delete under each top-level prefix.
+func printPlan(w io.Writer, plan *s3svc.Plan) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	// nolint [:gas]
+	fmt.Fprintln(tw, "PREFIX\tVERSIONS\tDELETE MARKERS\tBYTES\t")
+
+	prefixes := make([]string, 0, len(plan.Prefixes))
+	for prefix := range plan.Prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		count := plan.Prefixes[prefix]
+		name := prefix
+		if name == "" {
+			name = "(no prefix)"
+		}
+
+		// nolint [:gas]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t\n", name, count.Versions, count.DeleteMarkers, count.Bytes)
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t\n", plan.Versions, plan.DeleteMarkers, plan.Bytes)
+
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "Package: commands => func: printPlan => method call tabwriter.Writer.Flush failed\n")
+	}
+
+	return nil
+}
+
+// plan prints what an empty-bucket run would delete, and optionally writes the full list to PlanFile.
+func (cmd *EmptyBucketCommand) plan(s3client *s3svc.Client) error {
+	source, err := cmd.source(s3client, checkpoint.Marker{})
+	if err != nil {
+		return err
+	}
+
+	var (
+		listing io.Writer
+		buf     *bufio.Writer
+	)
+
+	if cmd.PlanFile != "" {
+		f, err := os.Create(cmd.PlanFile)
+		if err != nil {
+			return errors.Wrap(err, "Package: commands => func: plan => func call os.Create failed\n")
+		}
+
+		// nolint [:errcheck]
+		defer f.Close()
+
+		buf = bufio.NewWriter(f)
+		listing = buf
+	}
+
+	plan, err := s3svc.PlanVersions(source, listing)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: plan => func call s3svc.PlanVersions failed\n")
+	}
+
+	if buf != nil {
+		if err := buf.Flush(); err != nil {
+			return errors.Wrap(err, "Package: commands => func: plan => method call bufio.Writer.Flush failed\n")
+		}
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Dry run, nothing will be deleted from s3://%s\n\n", cmd.bucket)
+
+	if err := printPlan(os.Stdout, plan); err != nil {
+		return err
+	}
+
+	if cmd.DeleteBucket {
+		uploads, err := s3client.ListUploads(cmd.bucket)
+		if err != nil {
+			return errors.Wrap(err, "Package: commands => func: plan => method call s3svc.Client.ListUploads failed\n")
+		}
+
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "\nThen %d multipart uploads would be aborted and the bucket deleted\n", len(uploads))
+	}
 
+	if cmd.PlanFile != "" {
 		// nolint [:gas]
-		fmt.Fprintln(os.Stdout, resp)
+		fmt.Fprintf(os.Stdout, "\nFull listing written to %s\n", cmd.PlanFile)
 	}
 
 	return nil
@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/GetTerminus/s3helper/lib/audit"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/checkpoint"
+	"github.com/pkg/errors"
+)
+
+// bucketRun is one of several buckets empty-bucket works on, and how emptying it went.
+type bucketRun struct {
+	cmd      EmptyBucketCommand
+	s3client *s3svc.Client
+	report   *deleteReport
+
+	totals  *s3svc.DeleteTotals
+	deleted bool
+	err     error
+}
+
+// emptyBuckets empties several buckets, up to --bucket-concurrency of them at the same time, each
+// through a client for its own region, and finishes with a summary of every bucket. Each bucket is
+// emptied the way a single one would be, apart from what only makes sense for one bucket.
+func (cmd *EmptyBucketCommand) emptyBuckets(buckets []string) error {
+	if cmd.Checkpoint != "" || cmd.Resume || cmd.PlanFile != "" || cmd.MFASerial != "" {
+		return errors.New("Package: commands => func: emptyBuckets => --checkpoint, --resume, --plan-file and --mfa-serial work on a single bucket\n")
+	}
+
+	if !cmd.DryRun {
+		if err := checkInteractive(cmd.Yes); err != nil {
+			return err
+		}
+	}
+
+	var auditLog *audit.Log
+	if cmd.AuditLog != "" {
+		var err error
+		auditLog, err = audit.Open(cmd.AuditLog)
+		if err != nil {
+			return errors.Wrap(err, "Package: commands => func: emptyBuckets => func call audit.Open failed\n")
+		}
+
+		// nolint [:errcheck]
+		defer auditLog.Close()
+	}
+
+	runs := make([]*bucketRun, len(buckets))
+	regions := make([]string, len(buckets))
+	s3clients := make([]*s3svc.Client, len(buckets))
+
+	for i, bucket := range buckets {
+		run := &bucketRun{cmd: *cmd, report: newDeleteReport(bucket, auditLog)}
+		run.cmd.bucket = bucket
+		run.cmd.region = bucketRegion(bucket)
+		run.s3client = run.cmd.newClient(
+			s3svc.WithConcurrency(cmd.Concurrency),
+			s3svc.WithMaxRPS(cmd.MaxRPS),
+			s3svc.WithOutcomes(run.report.add),
+		)
+
+		runs[i], regions[i], s3clients[i] = run, run.cmd.region, run.s3client
+	}
+
+	if cmd.DryRun {
+		for _, run := range runs {
+			if err := run.cmd.checkLocks(run.s3client, checkpoint.Marker{}); err != nil {
+				return err
+			}
+
+			if err := run.cmd.plan(run.s3client); err != nil {
+				return err
+			}
+
+			// nolint [:gas]
+			fmt.Fprintln(os.Stdout)
+		}
+
+		return nil
+	}
+
+	if err := confirmBuckets(cmd.Yes, buckets, regions, cmd.details()...); err != nil {
+		return err
+	}
+
+	// other output goes to the terminal while buckets are emptied, so progress is logged rather than redrawn
+	display := newLogDisplay()
+	display.Start(clientCounts(s3clients...))
+
+	workers := cmd.BucketConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan *bucketRun)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for run := range queue {
+				run.empty()
+			}
+		}()
+	}
+
+	for _, run := range runs {
+		queue <- run
+	}
+	close(queue)
+	wg.Wait()
+
+	display.Stop()
+
+	return printBucketRuns(runs)
+}
+
+// empty deletes what the command selects from the run's bucket, and the bucket itself with --delete-bucket.
+func (run *bucketRun) empty() {
+	if run.err = run.cmd.checkLocks(run.s3client, checkpoint.Marker{}); run.err != nil {
+		return
+	}
+
+	source, err := run.cmd.source(run.s3client, checkpoint.Marker{})
+	if err != nil {
+		run.err = err
+		return
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Deleting contents of s3://%s\n", run.cmd.bucket)
+
+	run.totals, err = run.s3client.DeleteVersions(run.cmd.bucket, source)
+	if err != nil {
+		run.err = errors.Wrap(err, "Package: commands => func: empty => method call s3svc.Client.DeleteVersions failed\n")
+		return
+	}
+
+	if run.totals.Failed > 0 {
+		run.err = errors.Errorf("Package: commands => func: empty => failed to delete %d versions and delete markers from s3://%s\n", run.totals.Failed, run.cmd.bucket)
+		return
+	}
+
+	if run.cmd.DeleteBucket {
+		run.err = run.cmd.deleteBucket(run.s3client)
+		run.deleted = run.err == nil
+	}
+}
+
+// printBucketRuns writes a table of how emptying each bucket went, followed by the failed deletes of
+// every bucket that had some, and returns an error if any bucket failed.
+func printBucketRuns(runs []*bucketRun) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	// nolint [:gas]
+	fmt.Fprintln(tw, "\nBUCKET\tREGION\tVERSIONS\tDELETE MARKERS\tBYTES\tFAILED\tRESULT\t")
+
+	failed := 0
+	for _, run := range runs {
+		totals := run.totals
+		if totals == nil {
+			totals = &s3svc.DeleteTotals{}
+		}
+
+		result := "emptied"
+		switch {
+		case totals.Failed > 0:
+			failed++
+			result = "incomplete"
+		case run.err != nil:
+			failed++
+			result = "error: " + strings.TrimSpace(errors.Cause(run.err).Error())
+		case run.deleted:
+			result = "deleted"
+		}
+
+		// nolint [:gas]
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t\n", run.cmd.bucket, run.cmd.region, totals.Deleted-totals.DeleteMarkers, totals.DeleteMarkers, totals.Bytes, totals.Failed, result)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "Package: commands => func: printBucketRuns => method call tabwriter.Writer.Flush failed\n")
+	}
+
+	for _, run := range runs {
+		if run.totals != nil && run.totals.Failed > 0 {
+			// nolint [:gas]
+			fmt.Fprintf(os.Stderr, "\ns3://%s: ", run.cmd.bucket)
+			run.report.printFailures(os.Stderr)
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("Package: commands => func: printBucketRuns => failed to empty %d of %d buckets\n", failed, len(runs))
+	}
+
+	return nil
+}
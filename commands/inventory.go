@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/inventory"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// InventoryCommand represents the options that can be passed to the inventory subcommand.
+type InventoryCommand struct {
+	Manifest string   `short:"m" long:"manifest" value-name:"location" description:"the manifest.json of the inventory report, as s3://bucket/key or a local path" required:"true"`
+	Prefixes []string `long:"prefix" value-name:"prefix" description:"only count keys under this prefix, may be repeated" required:"false"`
+}
+
+func init() {
+	var cmd InventoryCommand
+
+	// nolint [:errcheck]
+	parser.OptParser.AddCommand(
+		"inventory",
+		"Summarize an S3 Inventory report",
+		"Read the manifest and data files of an S3 Inventory report, check its schema, and count the versions, delete markers, and bytes it lists under each top-level prefix, without listing the bucket",
+		&cmd,
+	)
+}
+
+// Execute implements the interface for the go-flags subcommand.
+func (cmd *InventoryCommand) Execute(args []string) error {
+	manifest, open, err := openInventory(cmd.Manifest)
+	if err != nil {
+		return err
+	}
+
+	size := int64(0)
+	for _, file := range manifest.Files {
+		size += file.Size
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Bucket:   s3://%s\nCreated:  %s\nSchema:   %s\nFiles:    %d (%d bytes)\n", manifest.SourceBucket, manifest.Created().Format(time.RFC3339), manifest.FileSchema, len(manifest.Files), size)
+
+	if !manifest.Versioned() {
+		// nolint [:gas]
+		fmt.Fprintln(os.Stdout, "The report only lists the current version of each key")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintln(os.Stdout)
+
+	var source s3svc.VersionSource = inventory.NewReader(manifest, open)
+	if len(cmd.Prefixes) > 0 {
+		source = s3svc.FilterVersions(source, s3svc.UnderPrefixes(cmd.Prefixes...))
+	}
+
+	plan, err := s3svc.PlanVersions(source, nil)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => func call s3svc.PlanVersions failed\n")
+	}
+
+	return printPlan(os.Stdout, plan)
+}
+
+// openInventory reads the manifest of an inventory report from s3://bucket/key or a local path, and
+// returns it with the way to open its data files, from the same bucket or next to the local copy.
+func openInventory(location string) (*inventory.Manifest, inventory.Opener, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Package: commands => func: openInventory => func call os.Open failed\n")
+		}
+
+		// nolint [:errcheck]
+		defer f.Close()
+
+		manifest, err := inventory.ParseManifest(f)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Package: commands => func: openInventory => func call inventory.ParseManifest failed for %s\n", location)
+		}
+
+		return manifest, inventory.LocalOpener(location), nil
+	}
+
+	bucket, key, err := splitS3Location(location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s3client := s3svc.NewClient(s3.New(aws.Client.GetRegionSession(bucketRegion(bucket))), parser.GlobalOpts.Verbose)
+
+	body, err := s3client.GetObject(bucket, key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Package: commands => func: openInventory => method call s3svc.Client.GetObject failed\n")
+	}
+
+	// nolint [:errcheck]
+	defer body.Close()
+
+	manifest, err := inventory.ParseManifest(body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Package: commands => func: openInventory => func call inventory.ParseManifest failed for %s\n", location)
+	}
+
+	return manifest, inventory.S3Opener(s3client, bucket), nil
+}
+
+// splitS3Location returns the bucket and key of an s3://bucket/key location.
+func splitS3Location(location string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(location, "s3://"), "/", 2)
+	if !strings.HasPrefix(location, "s3://") || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("Package: commands => func: splitS3Location => %s is not an s3://bucket/key location\n", location)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/lifecyclebackup"
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// lifecycleBackupPath returns the --lifecycle-backup file, by default one named after the bucket in
+// the working directory.
+func (cmd *EmptyBucketCommand) lifecycleBackupPath() string {
+	if cmd.LifecycleBackup != "" {
+		return cmd.LifecycleBackup
+	}
+
+	return cmd.bucket + ".lifecycle-backup.json"
+}
+
+// viaLifecycle saves the bucket's lifecycle configuration to the backup file and installs rules that
+// have S3 expire everything under the prefixes, instead of deleting version by version. With prefixes
+// the rules go next to those the bucket has, which still apply to everything else, without any they
+// replace them.
+func (cmd *EmptyBucketCommand) viaLifecycle(s3client *s3svc.Client) error {
+	rules, err := s3client.LifecycleRules(cmd.bucket)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: viaLifecycle => method call s3svc.Client.LifecycleRules failed\n")
+	}
+
+	if s3svc.HasExpireRules(rules) {
+		return errors.Errorf("Package: commands => func: viaLifecycle => s3://%s already has s3helper expire rules, follow them with lifecycle-status\n", cmd.bucket)
+	}
+
+	expire, change := s3svc.ExpireRules(), fmt.Sprintf("replace the %d lifecycle rules of the bucket", len(rules))
+	if len(cmd.Prefixes) > 0 {
+		expire, change = s3svc.MergeExpireRules(rules, cmd.Prefixes...), fmt.Sprintf("add expire rules next to the %d lifecycle rules of the bucket", len(rules))
+	}
+	path := cmd.lifecycleBackupPath()
+
+	if cmd.DryRun {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "Dry run, the lifecycle configuration of s3://%s will not be changed\n\n", cmd.bucket)
+		printLifecycleRules("Current rules", rules)
+		// nolint [:gas]
+		fmt.Fprintln(os.Stdout)
+		printLifecycleRules("Rules that would be installed", expire)
+
+		return nil
+	}
+
+	it := s3client.NewVersionIterator(cmd.bucket, cmd.Prefixes...)
+	count, more, err := s3svc.CountVersions(it, estimateLimit)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: viaLifecycle => func call s3svc.CountVersions failed\n")
+	}
+
+	estimate := fmt.Sprintf("%d versions and delete markers", count)
+	if more {
+		estimate = "more than " + estimate
+	}
+
+	details := cmd.details(
+		"Objects:  "+estimate+", and incomplete multipart uploads, expired by S3 within a day or two",
+		fmt.Sprintf("Rules:    %s, saved to %s", change, path),
+		"Beware:   anything written to the bucket expires too until the rules are restored",
+	)
+
+	if err := confirmDestructive(cmd.Yes, cmd.bucket, cmd.region, details...); err != nil {
+		return err
+	}
+
+	if err := lifecyclebackup.Save(path, cmd.bucket, rules); err != nil {
+		return errors.Wrap(err, "Package: commands => func: viaLifecycle => func call lifecyclebackup.Save failed, restore an existing backup with --restore-lifecycle or pass another --lifecycle-backup\n")
+	}
+
+	if err := s3client.PutLifecycleRules(cmd.bucket, expire); err != nil {
+		// the bucket kept its configuration, so the backup would only get in the way of the next try
+
+		// nolint [:errcheck]
+		os.Remove(path)
+		return errors.Wrap(err, "Package: commands => func: viaLifecycle => method call s3svc.Client.PutLifecycleRules failed\n")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Installed %d lifecycle rules on s3://%s, the previous %d rules are saved in %s\n", len(expire), cmd.bucket, len(rules), path)
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Follow the expiry with: s3helper lifecycle-status -b %s --interval 1h\n", cmd.bucket)
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Once it is done, put the previous rules back with: s3helper empty-bucket -b %s --restore-lifecycle --lifecycle-backup %s\n", cmd.bucket, path)
+
+	return nil
+}
+
+// restoreLifecycle puts back the lifecycle configuration --via-lifecycle saved to the backup file, and
+// removes the file.
+func (cmd *EmptyBucketCommand) restoreLifecycle(s3client *s3svc.Client) error {
+	path := cmd.lifecycleBackupPath()
+
+	backup, err := lifecyclebackup.Load(path, cmd.bucket)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: restoreLifecycle => func call lifecyclebackup.Load failed\n")
+	}
+
+	rules, err := s3client.LifecycleRules(cmd.bucket)
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: restoreLifecycle => method call s3svc.Client.LifecycleRules failed\n")
+	}
+
+	if !s3svc.HasExpireRules(rules) {
+		return errors.Errorf("Package: commands => func: restoreLifecycle => s3://%s has no s3helper expire rules, its lifecycle configuration was changed since, not replacing it, compare it with %s\n", cmd.bucket, path)
+	}
+
+	if err := s3client.PutLifecycleRules(cmd.bucket, backup.Rules); err != nil {
+		return errors.Wrap(err, "Package: commands => func: restoreLifecycle => method call s3svc.Client.PutLifecycleRules failed\n")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Restored the %d lifecycle rules s3://%s had on %s\n", len(backup.Rules), cmd.bucket, backup.Saved.Format(time.RFC3339))
+
+	if err := os.Remove(path); err != nil {
+		return errors.Wrap(err, "Package: commands => func: restoreLifecycle => func call os.Remove failed\n")
+	}
+
+	return nil
+}
+
+// printLifecycleRules writes the ID, prefix, and status of each rule under a title.
+func printLifecycleRules(title string, rules []*s3.LifecycleRule) {
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "%s:\n", title)
+
+	if len(rules) == 0 {
+		// nolint [:gas]
+		fmt.Fprintln(os.Stdout, "  none")
+	}
+
+	for _, rule := range rules {
+		prefix := awssdk.StringValue(rule.Prefix)
+		if rule.Filter != nil && rule.Filter.Prefix != nil {
+			prefix = awssdk.StringValue(rule.Filter.Prefix)
+		}
+		if rule.Filter != nil && rule.Filter.And != nil {
+			prefix = awssdk.StringValue(rule.Filter.And.Prefix)
+		}
+
+		if prefix == "" {
+			prefix = "(whole bucket)"
+		}
+
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "  %s  %s  %s\n", awssdk.StringValue(rule.ID), prefix, strings.ToLower(awssdk.StringValue(rule.Status)))
+	}
+}
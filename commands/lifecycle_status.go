@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/inventory"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// LifecycleStatusCommand represents the options that can be passed to the lifecycle-status subcommand.
+type LifecycleStatusCommand struct {
+	Bucket    string        `short:"b" long:"bucket" value-name:"bucket" description:"the bucket being emptied with empty-bucket --via-lifecycle" required:"true"`
+	Prefixes  []string      `long:"prefix" value-name:"prefix" description:"only count keys under this prefix, may be repeated" required:"false"`
+	Interval  time.Duration `long:"interval" value-name:"duration" description:"check again at this interval (1h, 30m) until nothing is left, instead of checking once" required:"false"`
+	Inventory string        `long:"inventory" value-name:"location" description:"count from an S3 Inventory report of the bucket until fewer than 10000 versions are left, listing only counts that far: the manifest.json of a report, or s3://bucket/prefix/ of the inventory configuration to use its newest report" required:"false"`
+
+	// belowLimit is set once the report counted fewer versions than a listing can, from then on the
+	// listing gives an exact and more recent count
+	belowLimit bool
+}
+
+func init() {
+	var cmd LifecycleStatusCommand
+
+	// nolint [:errcheck]
+	parser.OptParser.AddCommand(
+		"lifecycle-status",
+		"Follow an s3 bucket being emptied by lifecycle rules",
+		"Count the versions, delete markers, and multipart uploads left in an s3 bucket that empty-bucket --via-lifecycle is emptying, and check its expire rules are still in place. Listing stops counting at 10000 versions, pass --inventory to follow the count above that",
+		&cmd,
+	)
+}
+
+// Execute implements the interface for the go-flags subcommand.
+func (cmd *LifecycleStatusCommand) Execute(args []string) error {
+	svc := s3.New(aws.Client.GetRegionSession(bucketRegion(cmd.Bucket)))
+	s3client := s3svc.NewClient(svc, parser.GlobalOpts.Verbose)
+
+	for {
+		done, err := cmd.check(s3client)
+		if err != nil || done || cmd.Interval <= 0 {
+			return err
+		}
+
+		time.Sleep(cmd.Interval)
+	}
+}
+
+// check prints what is left in the bucket and whether the expire rules are installed, and reports
+// whether there is nothing left.
+func (cmd *LifecycleStatusCommand) check(s3client *s3svc.Client) (bool, error) {
+	rules, err := s3client.LifecycleRules(cmd.Bucket)
+	if err != nil {
+		return false, errors.Wrap(err, "Package: commands => func: check => method call s3svc.Client.LifecycleRules failed\n")
+	}
+
+	versions, count := "", 0
+	if cmd.Inventory != "" && !cmd.belowLimit {
+		total, created, err := cmd.inventoryCount()
+		if err != nil {
+			return false, err
+		}
+
+		if total >= estimateLimit {
+			versions, count = fmt.Sprintf("%d (inventory of %s)", total, created.Format(time.RFC3339)), int(total)
+		} else {
+			cmd.belowLimit = true
+		}
+	}
+
+	if versions == "" {
+		var more bool
+		count, more, err = s3svc.CountVersions(s3client.NewVersionIterator(cmd.Bucket, cmd.Prefixes...), estimateLimit)
+		if err != nil {
+			return false, errors.Wrap(err, "Package: commands => func: check => func call s3svc.CountVersions failed\n")
+		}
+
+		versions = fmt.Sprintf("%d", count)
+		if more {
+			versions = fmt.Sprintf("at least %d (listing stops counting there, pass --inventory for the full count)", count)
+		}
+	}
+
+	uploads, err := s3client.ListUploads(cmd.Bucket, cmd.Prefixes...)
+	if err != nil {
+		return false, errors.Wrap(err, "Package: commands => func: check => method call s3svc.Client.ListUploads failed\n")
+	}
+
+	installed := "installed"
+	if !s3svc.HasExpireRules(rules) {
+		installed = "not installed"
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "%s  s3://%s: %s versions and delete markers, %d multipart uploads left, expire rules %s\n", time.Now().Format(time.RFC3339), cmd.Bucket, versions, len(uploads), installed)
+
+	done := count == 0 && len(uploads) == 0
+	if done && s3svc.HasExpireRules(rules) {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "Nothing is left, put the previous lifecycle rules back with: s3helper empty-bucket -b %s --restore-lifecycle\n", cmd.Bucket)
+	}
+
+	return done, nil
+}
+
+// inventoryCount returns how many versions and delete markers under the prefixes the --inventory
+// report lists, and when S3 made it. A configuration's newest report is looked up every time, S3 adds
+// one a day or a week.
+func (cmd *LifecycleStatusCommand) inventoryCount() (int64, time.Time, error) {
+	location := cmd.Inventory
+	if path.Base(location) != "manifest.json" {
+		bucket, prefix, err := splitS3Location(strings.TrimSuffix(location, "/") + "/")
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+
+		s3client := s3svc.NewClient(s3.New(aws.Client.GetRegionSession(bucketRegion(bucket))), parser.GlobalOpts.Verbose)
+
+		folders, err := s3client.ListFolders(bucket, prefix)
+		if err != nil {
+			return 0, time.Time{}, errors.Wrap(err, "Package: commands => func: inventoryCount => method call s3svc.Client.ListFolders failed\n")
+		}
+
+		key, ok := inventory.LatestManifest(folders)
+		if !ok {
+			return 0, time.Time{}, errors.Errorf("Package: commands => func: inventoryCount => there is no inventory report under %s\n", location)
+		}
+		location = "s3://" + bucket + "/" + key
+	}
+
+	manifest, open, err := openInventory(location)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	if manifest.SourceBucket != cmd.Bucket {
+		return 0, time.Time{}, errors.Errorf("Package: commands => func: inventoryCount => %s is a report of s3://%s, not s3://%s\n", location, manifest.SourceBucket, cmd.Bucket)
+	}
+
+	source := s3svc.FilterVersions(inventory.NewReader(manifest, open), s3svc.UnderPrefixes(cmd.Prefixes...))
+
+	plan, err := s3svc.PlanVersions(source, nil)
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "Package: commands => func: inventoryCount => func call s3svc.PlanVersions failed\n")
+	}
+
+	return plan.Versions + plan.DeleteMarkers, manifest.Created(), nil
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/prompt"
+	"github.com/pkg/errors"
+)
+
+// mfaWindow is how long a TOTP code is used before the user is asked for a new one.
+const mfaWindow = 30 * time.Second
+
+// mfaOption returns the client option that sends a token from the MFA device serial with every
+// delete, for buckets with MFA Delete enabled. The user is asked for a code on the terminal at the
+// first delete and again whenever the last one has expired or S3 rejected it, holding pause meanwhile
+// so that nothing else draws over the question.
+func mfaOption(serial string, pause sync.Locker) (func(*s3svc.Client), error) {
+	if !prompt.IsTerminal(os.Stdin) {
+		return nil, errors.New("Package: commands => func: mfaOption => stdin is not a terminal, --mfa-serial needs one to ask for MFA codes\n")
+	}
+
+	token := func() (string, error) {
+		pause.Lock()
+		defer pause.Unlock()
+
+		code, err := prompt.Ask(os.Stdin, os.Stderr, "MFA code for "+serial+": ")
+		if err != nil {
+			return "", errors.Wrap(err, "Package: commands => func: mfaOption => func call prompt.Ask failed\n")
+		}
+
+		return code, nil
+	}
+
+	return s3svc.WithMFA(serial, mfaWindow, token), nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"os"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/GetTerminus/s3helper/lib/progress"
+	"github.com/GetTerminus/s3helper/lib/prompt"
+)
+
+// newDisplay returns the progress display for a deletion on stderr. It redraws a status line in place
+// on a terminal, unless --verbose lists every version there as well, and logs a line every 30 seconds
+// otherwise. Asking for MFA codes to renew assumed role credentials pauses it.
+func newDisplay() *progress.Display {
+	if prompt.IsTerminal(os.Stderr) && !parser.GlobalOpts.Verbose {
+		display := progress.New(os.Stderr, true, 500*time.Millisecond)
+		aws.Client.SetPause(display)
+
+		return display
+	}
+
+	return newLogDisplay()
+}
+
+// newLogDisplay returns a progress display that logs a line to stderr every 30 seconds, for when
+// other output goes to the terminal at the same time.
+func newLogDisplay() *progress.Display {
+	display := progress.New(os.Stderr, false, 30*time.Second)
+	aws.Client.SetPause(display)
+
+	return display
+}
+
+// clientCounts reads the counters of the clients for a progress display, adding them up.
+func clientCounts(s3clients ...*s3svc.Client) func() progress.Counts {
+	return func() progress.Counts {
+		var counts progress.Counts
+		for _, s3client := range s3clients {
+			counters := s3client.Counters()
+			counts.Listed += counters.Listed
+			counts.Deleted += counters.Deleted
+			counts.Failed += counters.Failed
+		}
+
+		return counts
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// PruneVersionsCommand represents the options that can be passed to the prune-versions subcommand.
+type PruneVersionsCommand struct {
+	Bucket      string   `short:"b" long:"bucket" value-name:"bucket" description:"the bucket to prune" required:"true"`
+	Keep        int      `short:"k" long:"keep" value-name:"n" description:"how many of the newest versions of each key to keep, including the current one" required:"true"`
+	Prefixes    []string `long:"prefix" value-name:"prefix" description:"only prune keys under this prefix, may be repeated" required:"false"`
+	DryRun      bool     `long:"dry-run" description:"count what would be deleted without deleting anything" required:"false"`
+	Concurrency int      `short:"c" long:"concurrency" value-name:"n" description:"number of DeleteObjects requests to run in parallel" required:"false" default:"4"`
+	MaxRPS      float64  `long:"max-rps" value-name:"n" description:"send at most this many requests per second, concurrency also drops by itself while S3 answers with SlowDown" required:"false"`
+	MFASerial   string   `long:"mfa-serial" value-name:"arn" description:"serial number or ARN of the MFA device, for buckets with MFA Delete, asks for a code as the run goes" required:"false"`
+	Yes         bool     `short:"y" long:"yes" description:"do not ask for confirmation, required when stdin is not a terminal" required:"false"`
+}
+
+func init() {
+	var cmd PruneVersionsCommand
+
+	// nolint [:errcheck]
+	parser.OptParser.AddCommand(
+		"prune-versions",
+		"Keep only the newest versions of each key in an s3 bucket",
+		"Delete every version of each key in an s3 bucket except the newest ones, the current version or delete marker is never deleted",
+		&cmd,
+	)
+}
+
+// Execute implements the interface for the go-flags subcommand.
+func (cmd *PruneVersionsCommand) Execute(args []string) error {
+	if cmd.Keep < 1 {
+		return errors.New("Package: commands => func: Execute => --keep must be at least 1, the current version is always kept\n")
+	}
+
+	if !cmd.DryRun {
+		if err := checkInteractive(cmd.Yes); err != nil {
+			return err
+		}
+	}
+
+	report := newDeleteReport(cmd.Bucket, nil)
+	display := newDisplay()
+
+	options := []func(*s3svc.Client){s3svc.WithConcurrency(cmd.Concurrency), s3svc.WithMaxRPS(cmd.MaxRPS), s3svc.WithOutcomes(report.add)}
+	if cmd.MFASerial != "" && !cmd.DryRun {
+		option, err := mfaOption(cmd.MFASerial, display)
+		if err != nil {
+			return err
+		}
+		options = append(options, option)
+	}
+
+	region := bucketRegion(cmd.Bucket)
+	awsSession := aws.Client.GetRegionSession(region)
+	s3client := s3svc.NewClient(s3.New(awsSession), parser.GlobalOpts.Verbose, options...)
+
+	source := s3svc.FilterKeys(s3client.NewVersionIterator(cmd.Bucket, cmd.Prefixes...), s3svc.KeepNewest(cmd.Keep))
+
+	if cmd.DryRun {
+		plan, err := s3svc.PlanVersions(source, nil)
+		if err != nil {
+			return errors.Wrap(err, "Package: commands => func: Execute => func call s3svc.PlanVersions failed\n")
+		}
+
+		// nolint [:gas]
+		fmt.Fprintf(os.Stdout, "Dry run, nothing will be deleted from s3://%s\n\n", cmd.Bucket)
+
+		return printPlan(os.Stdout, plan)
+	}
+
+	details := []string{}
+	if len(cmd.Prefixes) > 0 {
+		details = append(details, "Prefixes: "+strings.Join(cmd.Prefixes, ", "))
+	}
+	details = append(details, fmt.Sprintf("Keeping:  the newest %d versions of each key, deleting all older versions", cmd.Keep))
+
+	if err := confirmDestructive(cmd.Yes, cmd.Bucket, region, details...); err != nil {
+		return err
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Pruning s3://%s to the newest %d versions of each key\n", cmd.Bucket, cmd.Keep)
+
+	display.Start(clientCounts(s3client))
+	totals, err := s3client.DeleteVersions(cmd.Bucket, source)
+	display.Stop()
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => method call s3svc.Client.DeleteVersions failed\n")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Deleted %d older versions (%d bytes) and %d delete markers\n", totals.Deleted-totals.DeleteMarkers, totals.Bytes, totals.DeleteMarkers)
+
+	if totals.Failed > 0 {
+		report.printFailures(os.Stderr)
+		return errors.Errorf("Package: commands => func: Execute => failed to delete %d versions and delete markers from s3://%s\n", totals.Failed, cmd.Bucket)
+	}
+
+	return nil
+}
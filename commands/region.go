@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// locationRegion is where GetBucketLocation is sent, it answers for buckets in every region from there.
+const locationRegion = "us-east-1"
+
+// bucketRegion returns the region bucket lives in, or --region when it can't be looked up, e.g.
+// without the s3:GetBucketLocation permission.
+func bucketRegion(bucket string) string {
+	s3client := s3svc.NewClient(s3.New(aws.Client.GetRegionSession(locationRegion)), parser.GlobalOpts.Verbose)
+
+	region, err := s3client.BucketRegion(bucket)
+	if err != nil {
+		// nolint [:gas]
+		fmt.Fprintf(os.Stderr, "Could not look up the region of s3://%s, using %s: %s\n", bucket, parser.GlobalOpts.Region, strings.TrimSpace(errors.Cause(err).Error()))
+		return parser.GlobalOpts.Region
+	}
+
+	return region
+}
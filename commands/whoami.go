@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GetTerminus/s3helper/lib/aws"
+	"github.com/GetTerminus/s3helper/lib/parser"
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/pkg/errors"
+)
+
+// WhoamiCommand represents the options that can be passed to the whoami subcommand.
+type WhoamiCommand struct{}
+
+// providerSources says where each credentials provider of the chain takes credentials from.
+var providerSources = map[string]string{
+	credentials.EnvProviderName:         "environment variables",
+	credentials.SharedCredsProviderName: "shared credentials file",
+	endpointcreds.ProviderName:          "container credentials endpoint (ECS, CodeBuild)",
+	ec2rolecreds.ProviderName:           "EC2 instance role",
+	stscreds.ProviderName:               "assumed --role-arn",
+}
+
+func init() {
+	var cmd WhoamiCommand
+
+	// nolint [:errcheck]
+	parser.OptParser.AddCommand(
+		"whoami",
+		"Show which credentials s3helper uses",
+		"Show which provider of the credential chain the credentials came from, and the account, ARN, and user id they belong to",
+		&cmd,
+	)
+}
+
+// Execute implements the interface for the go-flags subcommand.
+func (cmd *WhoamiCommand) Execute(args []string) error {
+	provider, err := aws.Client.GetCredentialsProvider()
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => method call aws.Client.GetCredentialsProvider failed\n")
+	}
+
+	if source, ok := providerSources[provider]; ok {
+		provider += " (" + source + ")"
+	}
+
+	// the provider is worth knowing even when the credentials turn out not to work
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Provider: %s\n", provider)
+
+	identity, err := aws.Client.GetCallerIdentity()
+	if err != nil {
+		return errors.Wrap(err, "Package: commands => func: Execute => method call aws.Client.GetCallerIdentity failed\n")
+	}
+
+	// nolint [:gas]
+	fmt.Fprintf(os.Stdout, "Account:  %s\nARN:      %s\nUserId:   %s\n", awssdk.StringValue(identity.Account), awssdk.StringValue(identity.Arn), awssdk.StringValue(identity.UserId))
+
+	return nil
+}
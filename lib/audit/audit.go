@@ -0,0 +1,92 @@
+// Package audit keeps a record of every object version a command deleted or failed to delete, one
+// JSON object per line, for change management.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The results a Record can have.
+const (
+	ResultDeleted = "deleted"
+	ResultFailed  = "failed"
+)
+
+// Record is one line of the log, what happened to a single object version.
+type Record struct {
+	Bucket       string    `json:"bucket"`
+	Key          string    `json:"key"`
+	VersionID    string    `json:"version_id"`
+	DeleteMarker bool      `json:"delete_marker"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Timestamp    time.Time `json:"timestamp"`
+	Result       string    `json:"result"`
+
+	// Code and Message are the error S3 gave for a failed delete.
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Log appends records to a file as they come in.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the log at path for appending, creating it if needed. Records from earlier runs are
+// kept, so a resumed run adds to the log of the run it continues.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "package: audit => func: Open => func call os.OpenFile failed\n")
+	}
+
+	return &Log{file: file}, nil
+}
+
+// Write appends records to the log and syncs it to disk before returning, so that what was deleted
+// up to a crash is still on record. Records without a timestamp get the current time.
+func (l *Log) Write(records ...Record) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+
+	now := time.Now().UTC()
+	for _, record := range records {
+		if record.Timestamp.IsZero() {
+			record.Timestamp = now
+		}
+
+		if err := enc.Encode(record); err != nil {
+			return errors.Wrap(err, "package: audit => method: Write => method call json.Encoder.Encode failed\n")
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "package: audit => method: Write => method call os.File.Write failed\n")
+	}
+
+	if err := l.file.Sync(); err != nil {
+		return errors.Wrap(err, "package: audit => method: Write => method call os.File.Sync failed\n")
+	}
+
+	return nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	if err := l.file.Close(); err != nil {
+		return errors.Wrap(err, "package: audit => method: Close => method call os.File.Close failed\n")
+	}
+
+	return nil
+}
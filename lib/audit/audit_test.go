@@ -0,0 +1,89 @@
+package audit_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/audit"
+)
+
+var _ = Describe("Log", func() {
+	var (
+		dir  string
+		path string
+
+		read = func() []audit.Record {
+			file, err := os.Open(path)
+			Expect(err).To(BeNil())
+			defer file.Close()
+
+			records := []audit.Record{}
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var record audit.Record
+				Expect(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+				records = append(records, record)
+			}
+			Expect(scanner.Err()).To(BeNil())
+
+			return records
+		}
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "audit")
+		Expect(err).To(BeNil())
+		path = filepath.Join(dir, "audit.jsonl")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should write one line per record, readable before the log is closed", func() {
+		log, err := audit.Open(path)
+		Expect(err).To(BeNil())
+		defer log.Close()
+
+		modified := time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)
+		Expect(log.Write(
+			audit.Record{Bucket: "bucket", Key: "a", VersionID: "v1", Size: 10, LastModified: modified, Result: audit.ResultDeleted},
+			audit.Record{Bucket: "bucket", Key: "b", VersionID: "v2", DeleteMarker: true, Result: audit.ResultFailed, Code: "AccessDenied"},
+		)).To(Succeed())
+
+		records := read()
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Key).To(Equal("a"))
+		Expect(records[0].LastModified).To(Equal(modified))
+		Expect(records[0].Timestamp.IsZero()).To(BeFalse())
+		Expect(records[1].DeleteMarker).To(BeTrue())
+		Expect(records[1].Code).To(Equal("AccessDenied"))
+	})
+
+	It("should add to the records of an earlier run", func() {
+		for _, key := range []string{"first", "second"} {
+			log, err := audit.Open(path)
+			Expect(err).To(BeNil())
+			Expect(log.Write(audit.Record{Key: key, Result: audit.ResultDeleted})).To(Succeed())
+			Expect(log.Close()).To(Succeed())
+		}
+
+		records := read()
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Key).To(Equal("first"))
+		Expect(records[1].Key).To(Equal("second"))
+	})
+
+	It("should return an error when the log can't be created", func() {
+		_, err := audit.Open(filepath.Join(dir, "missing", "audit.jsonl"))
+		Expect(err).NotTo(BeNil())
+	})
+})
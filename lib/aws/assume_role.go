@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/parser"
+	"github.com/GetTerminus/s3helper/lib/prompt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// roleExpiryWindow is how long before assumed role credentials run out they are renewed, so that
+// requests in flight during a long run never go out with credentials about to expire.
+const roleExpiryWindow = 5 * time.Minute
+
+// assumeRoles returns credentials for the last of the --role-arn roles. The first role is assumed with
+// the credentials of base and every other one with those of the role before it. Each set renews itself
+// when it's about to run out, the ones earlier in the chain included, asking for a new MFA code for the
+// first role if --role-mfa-serial is given.
+func (c *client) assumeRoles(base *session.Session) *credentials.Credentials {
+	opts := parser.GlobalOpts
+	sess := base
+
+	var creds *credentials.Credentials
+	for i, arn := range opts.RoleARNs {
+		first, last := i == 0, i == len(opts.RoleARNs)-1
+
+		creds = stscreds.NewCredentials(sess, arn, func(p *stscreds.AssumeRoleProvider) {
+			p.RoleSessionName = opts.RoleSessionName
+			p.Duration = opts.Duration
+			p.ExpiryWindow = roleExpiryWindow
+
+			if last && opts.ExternalID != "" {
+				p.ExternalID = aws.String(opts.ExternalID)
+			}
+
+			if first && opts.RoleMFASerial != "" {
+				p.SerialNumber = aws.String(opts.RoleMFASerial)
+				p.TokenProvider = c.mfaToken(opts.RoleMFASerial, arn)
+			}
+		})
+
+		sess = base.Copy(&aws.Config{Credentials: creds})
+	}
+
+	return creds
+}
+
+// mfaToken returns a function that asks the user for a code from the MFA device serial to assume role
+// with, holding the pause lock meanwhile. Credentials only renew one at a time, so only one question
+// is ever asked at once.
+func (c *client) mfaToken(serial, role string) func() (string, error) {
+	return func() (string, error) {
+		if !prompt.IsTerminal(os.Stdin) {
+			return "", errors.New("package: aws => method: mfaToken => stdin is not a terminal, --role-mfa-serial needs one to ask for MFA codes\n")
+		}
+
+		pause := c.getPause()
+		pause.Lock()
+		defer pause.Unlock()
+
+		code, err := prompt.Ask(os.Stdin, os.Stderr, "MFA code for "+serial+" to assume "+role+": ")
+		if err != nil {
+			return "", errors.Wrap(err, "package: aws => method: mfaToken => func call prompt.Ask failed\n")
+		}
+
+		return code, nil
+	}
+}
+
+// SetPause makes questions asked while renewing credentials, like MFA codes for --role-arn, hold
+// pause, so that a progress display doesn't draw over them.
+func (c *client) SetPause(pause sync.Locker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pause = pause
+}
+
+// getPause returns the lock set with SetPause, or one that does nothing.
+func (c *client) getPause() sync.Locker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pause == nil {
+		return &sync.Mutex{}
+	}
+
+	return c.pause
+}
@@ -6,12 +6,19 @@ import (
 	"github.com/GetTerminus/s3helper/lib/parser"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
 )
 
 type client struct {
 	sess *session.Session
 	once sync.Once
+
+	// mu guards pause, which renewing credentials may read from any goroutine
+	mu    sync.Mutex
+	pause sync.Locker
 }
 
 // Session returns an authenticated AWS session, used to make API calls.
@@ -29,17 +36,68 @@ func (c *client) GetSession() *session.Session {
 				},
 			),
 		)
+
+		if len(parser.GlobalOpts.RoleARNs) > 0 {
+			c.sess = c.sess.Copy(&aws.Config{Credentials: c.assumeRoles(c.sess)})
+		}
 	})
 
 	return c.sess
 }
 
+// GetRegionSession returns a copy of the session that sends requests to region, for buckets that
+// live somewhere else than the region the session was created for.
+func (c *client) GetRegionSession(region string) *session.Session {
+	return c.GetSession().Copy(&aws.Config{Region: aws.String(region)})
+}
+
+// GetCallerIdentity returns the account and ARN of the credentials the session signs requests with.
+func (c *client) GetCallerIdentity() (*sts.GetCallerIdentityOutput, error) {
+	identity, err := sts.New(c.GetSession()).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "package: aws => method: GetCallerIdentity => method call sts.GetCallerIdentity failed\n")
+	}
+
+	return identity, nil
+}
+
+// GetCredentialsProvider returns the name of the provider the session's credentials came from, like
+// EnvProvider or EC2RoleProvider, retrieving the credentials if that hasn't happened yet.
+func (c *client) GetCredentialsProvider() (string, error) {
+	value, err := c.GetSession().Config.Credentials.Get()
+	if err != nil {
+		return "", errors.Wrap(err, "package: aws => method: GetCredentialsProvider => method call credentials.Credentials.Get failed\n")
+	}
+
+	return value.ProviderName, nil
+}
+
+// processCredentials returns the credentials requests are signed with. With a profile they come from
+// that profile of the shared credentials file. Otherwise they come from the first provider of the
+// chain that has any: the environment, the shared credentials file with the profile of AWS_PROFILE or
+// the default one, and then the container credentials endpoint on ECS and CodeBuild, or the instance
+// role on EC2.
 func processCredentials(profile string) *credentials.Credentials {
 	if profile != "" {
 		return credentials.NewSharedCredentials("", profile)
 	}
 
-	return credentials.NewEnvCredentials()
+	return credentials.NewCredentials(&credentials.ChainProvider{
+		// say why every provider failed rather than only that none had credentials
+		VerboseErrors: true,
+		Providers: []credentials.Provider{
+			&credentials.EnvProvider{},
+			&credentials.SharedCredentialsProvider{},
+			remoteProvider(),
+		},
+	})
+}
+
+// remoteProvider returns the provider for credentials the environment hands out: the container
+// credentials endpoint (endpointcreds) when ECS or CodeBuild set one, else the EC2 instance role
+// (ec2rolecreds) from the instance metadata service.
+func remoteProvider() credentials.Provider {
+	return defaults.RemoteCredProvider(*defaults.Config(), defaults.Handlers())
 }
 
 // Client is the singleton instance of the client struct.
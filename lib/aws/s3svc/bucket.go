@@ -0,0 +1,118 @@
+package s3svc
+
+import (
+	"io"
+	"path"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// Leftovers counts what is still stored in a bucket that should be empty.
+type Leftovers struct {
+	Versions int
+	Uploads  int
+}
+
+// Empty reports whether nothing was left.
+func (l *Leftovers) Empty() bool {
+	return l.Versions == 0 && l.Uploads == 0
+}
+
+// CheckEmpty lists the specified s3 bucket from scratch and counts the versions, delete markers, and
+// multipart uploads still in it, e.g. ones a writer added while the bucket was being emptied. Versions
+// are counted up to 1000, any number above zero means the bucket cannot be deleted.
+func (c *Client) CheckEmpty(bucket string) (*Leftovers, error) {
+	versions, _, err := CountVersions(c.NewVersionIterator(bucket), maxDeleteBatch)
+	if err != nil {
+		return nil, errors.Wrap(err, "package: s3svc => method: CheckEmpty => func call s3svc.CountVersions failed\n")
+	}
+
+	uploads, err := c.ListUploads(bucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "package: s3svc => method: CheckEmpty => method call s3svc.Client.ListUploads failed\n")
+	}
+
+	return &Leftovers{Versions: versions, Uploads: len(uploads)}, nil
+}
+
+// DeleteBucket deletes the specified s3 bucket, which S3 only allows once it is empty.
+func (c *Client) DeleteBucket(bucket string) error {
+	if _, err := c.s3api.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		return errors.Wrap(err, "package: s3svc => method: DeleteBucket => method call s3api.DeleteBucket failed\n")
+	}
+
+	return nil
+}
+
+// ListBuckets returns the names of the buckets the account owns that match the glob pattern, in the
+// syntax of path.Match, sorted by name.
+func (c *Client) ListBuckets(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, errors.Wrapf(err, "package: s3svc => method: ListBuckets => invalid bucket pattern %q\n", pattern)
+	}
+
+	resp, err := c.s3api.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "package: s3svc => method: ListBuckets => method call s3api.ListBuckets failed\n")
+	}
+
+	names := []string{}
+	for _, bucket := range resp.Buckets {
+		name := aws.StringValue(bucket.Name)
+
+		// the pattern was checked above, so Match can't fail
+		if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// BucketRegion returns the region the specified s3 bucket lives in.
+func (c *Client) BucketRegion(bucket string) (string, error) {
+	resp, err := c.s3api.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", errors.Wrap(err, "package: s3svc => method: BucketRegion => method call s3api.GetBucketLocation failed\n")
+	}
+
+	// buckets in us-east-1 and the old EU location report no or a legacy location constraint
+	return s3.NormalizeBucketLocation(aws.StringValue(resp.LocationConstraint)), nil
+}
+
+// GetObject returns the contents of the latest version of the specified key, which the caller must close.
+func (c *Client) GetObject(bucket, key string) (io.ReadCloser, error) {
+	resp, err := c.s3api.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "package: s3svc => method: GetObject => method call s3api.GetObject failed for s3://%s/%s\n", bucket, key)
+	}
+
+	return resp.Body, nil
+}
+
+// ListFolders returns the prefixes one level under prefix in the specified s3 bucket, the folders of
+// the S3 console, each ending in a slash.
+func (c *Client) ListFolders(bucket, prefix string) ([]string, error) {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String(prefix), Delimiter: aws.String("/")}
+
+	folders := []string{}
+	for {
+		resp, err := c.s3api.ListObjectsV2(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "package: s3svc => method: ListFolders => method call s3api.ListObjectsV2 failed\n")
+		}
+
+		for _, common := range resp.CommonPrefixes {
+			folders = append(folders, aws.StringValue(common.Prefix))
+		}
+
+		if !aws.BoolValue(resp.IsTruncated) {
+			return folders, nil
+		}
+		input.ContinuationToken = resp.NextContinuationToken
+	}
+}
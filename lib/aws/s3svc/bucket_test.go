@@ -0,0 +1,290 @@
+package s3svc_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc/s3svcfakes"
+)
+
+var _ = Describe("Bucket", func() {
+	var (
+		fake   *fakeBucket
+		fakeS3 *s3svcfakes.FakeAPI
+		client *s3svc.Client
+	)
+
+	BeforeEach(func() {
+		fake = &fakeBucket{pageSize: 2}
+		fakeS3 = newFakeS3(fake)
+		client = s3svc.NewClient(fakeS3, false)
+	})
+
+	Describe("ListUploads", func() {
+		BeforeEach(func() {
+			fake.addUpload("a/one", "u1")
+			fake.addUpload("a/one", "u2")
+			fake.addUpload("a/two", "u3")
+			fake.addUpload("b/one", "u4")
+			fake.addUpload("c", "u5")
+		})
+
+		It("should list every upload across pages", func() {
+			uploads, err := client.ListUploads("bucket")
+			Expect(err).To(BeNil())
+
+			ids := []string{}
+			for _, upload := range uploads {
+				ids = append(ids, upload.Key+"@"+upload.UploadID)
+			}
+			Expect(ids).To(Equal([]string{"a/one@u1", "a/one@u2", "a/two@u3", "b/one@u4", "c@u5"}))
+			Expect(uploads[0].Initiated).To(Equal(fakeEpoch))
+			Expect(fakeS3.ListMultipartUploadsCallCount()).To(Equal(3))
+
+			input := fakeS3.ListMultipartUploadsArgsForCall(1)
+			Expect(aws.StringValue(input.KeyMarker)).To(Equal("a/one"))
+			Expect(aws.StringValue(input.UploadIdMarker)).To(Equal("u2"))
+		})
+
+		It("should only list uploads under the prefixes", func() {
+			uploads, err := client.ListUploads("bucket", "b/", "c")
+			Expect(err).To(BeNil())
+			Expect(uploads).To(HaveLen(2))
+			Expect(aws.StringValue(fakeS3.ListMultipartUploadsArgsForCall(0).Prefix)).To(Equal("b/"))
+		})
+
+		It("should return an error when s3.ListMultipartUploads fails", func() {
+			fakeS3.ListMultipartUploadsStub = nil
+			fakeS3.ListMultipartUploadsReturns(nil, errors.New("fail"))
+
+			_, err := client.ListUploads("bucket")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("AddPartSizes", func() {
+		BeforeEach(func() {
+			fake.addUpload("a", "u1", 100, 200, 300)
+			fake.addUpload("b", "u2")
+		})
+
+		It("should count the parts of every upload across pages", func() {
+			uploads, err := client.ListUploads("bucket")
+			Expect(err).To(BeNil())
+
+			Expect(client.AddPartSizes("bucket", uploads)).To(Succeed())
+			Expect(uploads[0].Parts).To(Equal(3))
+			Expect(uploads[0].Size).To(Equal(int64(600)))
+			Expect(uploads[1].Parts).To(Equal(0))
+			Expect(uploads[1].Size).To(Equal(int64(0)))
+			Expect(fakeS3.ListPartsCallCount()).To(Equal(3))
+		})
+
+		It("should return an error when s3.ListParts fails", func() {
+			err := client.AddPartSizes("bucket", []*s3svc.Upload{{Key: "a", UploadID: "gone"}})
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("AbortUploads", func() {
+		BeforeEach(func() {
+			fake.addUpload("a", "u1")
+			fake.addUpload("b", "u2")
+		})
+
+		It("should abort every upload", func() {
+			uploads, err := client.ListUploads("bucket")
+			Expect(err).To(BeNil())
+
+			result := client.AbortUploads("bucket", uploads)
+			Expect(result.Aborted).To(Equal(uploads))
+			Expect(result.Failed).To(BeEmpty())
+			Expect(fake.remainingUploads()).To(BeEmpty())
+		})
+
+		It("should report uploads that could not be aborted and carry on", func() {
+			gone := &s3svc.Upload{Key: "a", UploadID: "gone"}
+
+			result := client.AbortUploads("bucket", []*s3svc.Upload{
+				{Key: "a", UploadID: "u1"},
+				gone,
+				{Key: "b", UploadID: "u2"},
+			})
+			Expect(result.Aborted).To(HaveLen(2))
+			Expect(result.Failed).To(HaveLen(1))
+			Expect(result.Failed[0].Upload).To(Equal(gone))
+			Expect(result.Failed[0].Err).NotTo(BeNil())
+			Expect(fake.remainingUploads()).To(BeEmpty())
+		})
+
+		Context("with concurrent workers", func() {
+			BeforeEach(func() {
+				client = s3svc.NewClient(fakeS3, false, s3svc.WithConcurrency(4))
+				for i := 0; i < 50; i++ {
+					fake.addUpload(fmt.Sprintf("key-%02d", i), fmt.Sprintf("id-%02d", i))
+				}
+			})
+
+			It("should abort every upload and keep the listing order", func() {
+				uploads, err := client.ListUploads("bucket")
+				Expect(err).To(BeNil())
+
+				result := client.AbortUploads("bucket", uploads)
+				Expect(result.Aborted).To(Equal(uploads))
+				Expect(fakeS3.AbortMultipartUploadCallCount()).To(Equal(52))
+				Expect(fake.remainingUploads()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("CheckEmpty", func() {
+		It("should report an empty bucket", func() {
+			leftovers, err := client.CheckEmpty("bucket")
+			Expect(err).To(BeNil())
+			Expect(leftovers.Empty()).To(BeTrue())
+		})
+
+		It("should count versions, delete markers, and uploads that are left", func() {
+			fake.addVersion("a", "a1")
+			fake.addDeleteMarker("b", "bdm")
+			fake.addVersion("c", "c1")
+			fake.addUpload("d", "u1")
+
+			leftovers, err := client.CheckEmpty("bucket")
+			Expect(err).To(BeNil())
+			Expect(leftovers.Empty()).To(BeFalse())
+			Expect(leftovers.Versions).To(Equal(3))
+			Expect(leftovers.Uploads).To(Equal(1))
+		})
+	})
+
+	Describe("DeleteBucket", func() {
+		It("should delete the bucket", func() {
+			Expect(client.DeleteBucket("bucket")).To(Succeed())
+			Expect(fakeS3.DeleteBucketCallCount()).To(Equal(1))
+			Expect(fakeS3.DeleteBucketArgsForCall(0)).To(Equal(&s3.DeleteBucketInput{Bucket: aws.String("bucket")}))
+		})
+
+		It("should return an error when s3.DeleteBucket fails", func() {
+			fakeS3.DeleteBucketReturns(nil, errors.New("BucketNotEmpty"))
+			Expect(client.DeleteBucket("bucket")).NotTo(Succeed())
+		})
+	})
+
+	Describe("ListBuckets", func() {
+		BeforeEach(func() {
+			fakeS3.ListBucketsReturns(&s3.ListBucketsOutput{Buckets: []*s3.Bucket{
+				{Name: aws.String("ci-preview-pr-12")},
+				{Name: aws.String("assets")},
+				{Name: aws.String("ci-preview-pr-3")},
+				{Name: aws.String("ci-main")},
+			}}, nil)
+		})
+
+		It("should return the matching buckets sorted by name", func() {
+			buckets, err := client.ListBuckets("ci-preview-pr-*")
+			Expect(err).To(BeNil())
+			Expect(buckets).To(Equal([]string{"ci-preview-pr-12", "ci-preview-pr-3"}))
+		})
+
+		It("should return an empty list when nothing matches", func() {
+			buckets, err := client.ListBuckets("prod-*")
+			Expect(err).To(BeNil())
+			Expect(buckets).To(BeEmpty())
+		})
+
+		It("should refuse an invalid pattern without calling s3", func() {
+			_, err := client.ListBuckets("ci-[")
+			Expect(err).NotTo(BeNil())
+			Expect(fakeS3.ListBucketsCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("BucketRegion", func() {
+		It("should return the location constraint of the bucket", func() {
+			fakeS3.GetBucketLocationReturns(&s3.GetBucketLocationOutput{LocationConstraint: aws.String("eu-central-1")}, nil)
+
+			region, err := client.BucketRegion("bucket")
+			Expect(err).To(BeNil())
+			Expect(region).To(Equal("eu-central-1"))
+			Expect(aws.StringValue(fakeS3.GetBucketLocationArgsForCall(0).Bucket)).To(Equal("bucket"))
+		})
+
+		It("should map an empty location constraint to us-east-1", func() {
+			fakeS3.GetBucketLocationReturns(&s3.GetBucketLocationOutput{}, nil)
+
+			region, err := client.BucketRegion("bucket")
+			Expect(err).To(BeNil())
+			Expect(region).To(Equal("us-east-1"))
+		})
+
+		It("should return an error when s3.GetBucketLocation fails", func() {
+			fakeS3.GetBucketLocationReturns(nil, errors.New("AccessDenied"))
+
+			_, err := client.BucketRegion("bucket")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("GetObject", func() {
+		It("should return the body of the object", func() {
+			fakeS3.GetObjectReturns(&s3.GetObjectOutput{Body: ioutil.NopCloser(strings.NewReader("contents"))}, nil)
+
+			body, err := client.GetObject("bucket", "a/key")
+			Expect(err).To(BeNil())
+
+			data, err := ioutil.ReadAll(body)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("contents"))
+
+			input := fakeS3.GetObjectArgsForCall(0)
+			Expect(aws.StringValue(input.Bucket)).To(Equal("bucket"))
+			Expect(aws.StringValue(input.Key)).To(Equal("a/key"))
+		})
+
+		It("should return an error when s3.GetObject fails", func() {
+			fakeS3.GetObjectReturns(nil, errors.New("NoSuchKey"))
+
+			_, err := client.GetObject("bucket", "a/key")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("ListFolders", func() {
+		It("should return the folders of every page", func() {
+			fakeS3.ListObjectsV2ReturnsOnCall(0, &s3.ListObjectsV2Output{
+				CommonPrefixes:        []*s3.CommonPrefix{{Prefix: aws.String("inv/2018-03-01T00-00Z/")}},
+				IsTruncated:           aws.Bool(true),
+				NextContinuationToken: aws.String("token"),
+			}, nil)
+			fakeS3.ListObjectsV2ReturnsOnCall(1, &s3.ListObjectsV2Output{
+				CommonPrefixes: []*s3.CommonPrefix{{Prefix: aws.String("inv/2018-03-02T00-00Z/")}, {Prefix: aws.String("inv/data/")}},
+			}, nil)
+
+			folders, err := client.ListFolders("bucket", "inv/")
+			Expect(err).To(BeNil())
+			Expect(folders).To(Equal([]string{"inv/2018-03-01T00-00Z/", "inv/2018-03-02T00-00Z/", "inv/data/"}))
+
+			first := fakeS3.ListObjectsV2ArgsForCall(0)
+			Expect(aws.StringValue(first.Prefix)).To(Equal("inv/"))
+			Expect(aws.StringValue(first.Delimiter)).To(Equal("/"))
+			Expect(aws.StringValue(fakeS3.ListObjectsV2ArgsForCall(1).ContinuationToken)).To(Equal("token"))
+		})
+
+		It("should return an error when s3.ListObjectsV2 fails", func() {
+			fakeS3.ListObjectsV2Returns(nil, errors.New("AccessDenied"))
+
+			_, err := client.ListFolders("bucket", "inv/")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package s3svc
+
+import "sync/atomic"
+
+// Counters are running totals of the versions a Client's DeleteVersions calls went through, Listed
+// counting what the source yielded and the others what became of it. Bytes is the size of the
+// deleted object versions.
+type Counters struct {
+	Listed  int64
+	Deleted int64
+	Failed  int64
+	Bytes   int64
+}
+
+// Counters returns the client's totals so far, it's safe to call while DeleteVersions runs, e.g. to
+// show progress.
+func (c *Client) Counters() Counters {
+	return Counters{
+		Listed:  atomic.LoadInt64(&c.counters.Listed),
+		Deleted: atomic.LoadInt64(&c.counters.Deleted),
+		Failed:  atomic.LoadInt64(&c.counters.Failed),
+		Bytes:   atomic.LoadInt64(&c.counters.Bytes),
+	}
+}
+
+// count adds the totals of a finished batch to the client's counters.
+func (c *Client) count(batch DeleteTotals) {
+	atomic.AddInt64(&c.counters.Deleted, int64(batch.Deleted))
+	atomic.AddInt64(&c.counters.Failed, int64(batch.Failed))
+	atomic.AddInt64(&c.counters.Bytes, batch.Bytes)
+}
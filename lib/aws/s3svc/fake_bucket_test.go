@@ -0,0 +1,382 @@
+package s3svc_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc/s3svcfakes"
+)
+
+// fakeEpoch is the LastModified time of the first entry added to a fakeBucket, every later entry is a
+// minute older so that versions of a key added in order are listed newest first.
+var fakeEpoch = time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeEntry is a single object version or delete marker stored in a fakeBucket.
+type fakeEntry struct {
+	id           int
+	key          string
+	versionID    string
+	deleteMarker bool
+	size         int64
+}
+
+// before reports whether e is listed ahead of the entry with the given key and id.
+func (e *fakeEntry) before(key string, id int) bool {
+	if e.key != key {
+		return e.key < key
+	}
+
+	return e.id < id
+}
+
+// fakeBucket is an in-memory stand-in for a versioned s3 bucket. Its methods can be
+// plugged into the stubs of s3svcfakes.FakeAPI so that listings honor the prefix and
+// markers of each request and deletions actually remove entries.
+type fakeBucket struct {
+	mu       sync.Mutex
+	entries  []*fakeEntry
+	pageSize int
+
+	// ids remembers the insertion order of every version id ever added, so that markers
+	// keep their position in the listing after the entry they point at is deleted
+	ids map[string]int
+
+	// failures maps a key to the error code DeleteObjects reports for each of its versions
+	failures map[string]string
+
+	// uploads are the multipart uploads in progress, in listing order, with the sizes of their parts by upload id
+	uploads []*s3.MultipartUpload
+	parts   map[string][]int64
+}
+
+func (b *fakeBucket) add(e *fakeEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ids == nil {
+		b.ids = map[string]int{}
+	}
+	e.id = len(b.ids)
+	b.ids[e.versionID] = e.id
+	b.entries = append(b.entries, e)
+
+	// s3 lists keys in order, versions of a key stay in insertion order
+	sort.Slice(b.entries, func(i, j int) bool { return b.entries[i].before(b.entries[j].key, b.entries[j].id) })
+}
+
+func (b *fakeBucket) addVersion(key, versionID string) {
+	b.add(&fakeEntry{key: key, versionID: versionID, size: 1024})
+}
+
+func (b *fakeBucket) addDeleteMarker(key, versionID string) {
+	b.add(&fakeEntry{key: key, versionID: versionID, deleteMarker: true})
+}
+
+// remaining returns "key@versionId" for every version and delete marker left in the bucket.
+func (b *fakeBucket) remaining() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	left := []string{}
+	for _, e := range b.entries {
+		left = append(left, e.key+"@"+e.versionID)
+	}
+
+	return left
+}
+
+func (b *fakeBucket) ListObjectVersions(input *s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prefix := aws.StringValue(input.Prefix)
+	keyMarker := aws.StringValue(input.KeyMarker)
+	versionMarker := aws.StringValue(input.VersionIdMarker)
+
+	pageSize := b.pageSize
+	if pageSize == 0 {
+		pageSize = 1000
+	}
+
+	// skip everything up to and including the markers
+	start := 0
+	if keyMarker != "" {
+		markerID, ok := b.ids[versionMarker]
+		if versionMarker == "" || !ok {
+			markerID = len(b.ids)
+		}
+
+		for start < len(b.entries) && !(&fakeEntry{key: keyMarker, id: markerID}).before(b.entries[start].key, b.entries[start].id) {
+			start++
+		}
+	}
+
+	// the first remaining entry of a key is its latest, entries added later are older
+	latest := map[string]bool{}
+	isLatest := func(e *fakeEntry) bool {
+		if latest[e.key] {
+			return false
+		}
+		latest[e.key] = true
+		return true
+	}
+	for i := 0; i < start; i++ {
+		isLatest(b.entries[i])
+	}
+
+	out := &s3.ListObjectVersionsOutput{IsTruncated: aws.Bool(false)}
+	count := 0
+	var last *fakeEntry
+	for i := start; i < len(b.entries); i++ {
+		e := b.entries[i]
+		if !strings.HasPrefix(e.key, prefix) {
+			continue
+		}
+
+		if count == pageSize {
+			out.IsTruncated = aws.Bool(true)
+			out.NextKeyMarker = aws.String(last.key)
+			out.NextVersionIdMarker = aws.String(last.versionID)
+			break
+		}
+		count++
+		last = e
+
+		entryIsLatest := isLatest(e)
+		lastModified := fakeEpoch.Add(-time.Duration(e.id) * time.Minute)
+
+		if e.deleteMarker {
+			out.DeleteMarkers = append(out.DeleteMarkers, &s3.DeleteMarkerEntry{
+				IsLatest:     aws.Bool(entryIsLatest),
+				Key:          aws.String(e.key),
+				LastModified: aws.Time(lastModified),
+				VersionId:    aws.String(e.versionID),
+			})
+			continue
+		}
+
+		out.Versions = append(out.Versions, &s3.ObjectVersion{
+			IsLatest:     aws.Bool(entryIsLatest),
+			Key:          aws.String(e.key),
+			LastModified: aws.Time(lastModified),
+			Size:         aws.Int64(e.size),
+			VersionId:    aws.String(e.versionID),
+		})
+	}
+
+	return out, nil
+}
+
+func (b *fakeBucket) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := &s3.DeleteObjectsOutput{}
+	for _, id := range input.Delete.Objects {
+		if code, ok := b.failures[*id.Key]; ok {
+			out.Errors = append(out.Errors, &s3.Error{
+				Code:      aws.String(code),
+				Key:       id.Key,
+				Message:   aws.String(code),
+				VersionId: id.VersionId,
+			})
+			continue
+		}
+
+		for i, e := range b.entries {
+			if e.key != *id.Key || e.versionID != aws.StringValue(id.VersionId) {
+				continue
+			}
+
+			b.entries = append(b.entries[:i], b.entries[i+1:]...)
+
+			deleted := &s3.DeletedObject{Key: id.Key, VersionId: id.VersionId}
+			if e.deleteMarker {
+				deleted.DeleteMarker = aws.Bool(true)
+			}
+			out.Deleted = append(out.Deleted, deleted)
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// addUpload starts a multipart upload with parts of the given sizes, uploads are listed by key and
+// then in the order they were added.
+func (b *fakeBucket) addUpload(key, uploadID string, partSizes ...int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.parts == nil {
+		b.parts = map[string][]int64{}
+	}
+	b.parts[uploadID] = partSizes
+
+	b.uploads = append(b.uploads, &s3.MultipartUpload{
+		Key:       aws.String(key),
+		UploadId:  aws.String(uploadID),
+		Initiated: aws.Time(fakeEpoch.Add(-time.Duration(len(b.uploads)) * time.Hour)),
+	})
+	sort.SliceStable(b.uploads, func(i, j int) bool { return *b.uploads[i].Key < *b.uploads[j].Key })
+}
+
+// remainingUploads returns "key@uploadId" for every multipart upload left in the bucket.
+func (b *fakeBucket) remainingUploads() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	left := []string{}
+	for _, u := range b.uploads {
+		left = append(left, *u.Key+"@"+*u.UploadId)
+	}
+
+	return left
+}
+
+func (b *fakeBucket) ListMultipartUploads(input *s3.ListMultipartUploadsInput) (*s3.ListMultipartUploadsOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prefix := aws.StringValue(input.Prefix)
+	keyMarker := aws.StringValue(input.KeyMarker)
+	uploadMarker := aws.StringValue(input.UploadIdMarker)
+
+	pageSize := b.pageSize
+	if pageSize == 0 {
+		pageSize = 1000
+	}
+
+	// skip everything up to and including the markers, without an upload id marker the whole key is skipped
+	start := 0
+	if keyMarker != "" {
+		for start < len(b.uploads) && *b.uploads[start].Key <= keyMarker {
+			start++
+			if *b.uploads[start-1].Key == keyMarker && *b.uploads[start-1].UploadId == uploadMarker {
+				break
+			}
+		}
+	}
+
+	out := &s3.ListMultipartUploadsOutput{IsTruncated: aws.Bool(false)}
+	for i := start; i < len(b.uploads); i++ {
+		u := b.uploads[i]
+		if !strings.HasPrefix(*u.Key, prefix) {
+			continue
+		}
+
+		if len(out.Uploads) == pageSize {
+			last := out.Uploads[len(out.Uploads)-1]
+			out.IsTruncated = aws.Bool(true)
+			out.NextKeyMarker = last.Key
+			out.NextUploadIdMarker = last.UploadId
+			break
+		}
+
+		out.Uploads = append(out.Uploads, u)
+	}
+
+	return out, nil
+}
+
+func (b *fakeBucket) AbortMultipartUpload(input *s3.AbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, u := range b.uploads {
+		if *u.Key == *input.Key && *u.UploadId == *input.UploadId {
+			b.uploads = append(b.uploads[:i], b.uploads[i+1:]...)
+			return &s3.AbortMultipartUploadOutput{}, nil
+		}
+	}
+
+	return nil, errors.New("NoSuchUpload")
+}
+
+func (b *fakeBucket) ListParts(input *s3.ListPartsInput) (*s3.ListPartsOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sizes, ok := b.parts[*input.UploadId]
+	if !ok {
+		return nil, errors.New("NoSuchUpload")
+	}
+
+	pageSize := b.pageSize
+	if pageSize == 0 {
+		pageSize = 1000
+	}
+
+	// part numbers start at 1, so the marker is also the index of the first part to list
+	start := int(aws.Int64Value(input.PartNumberMarker))
+
+	out := &s3.ListPartsOutput{IsTruncated: aws.Bool(false)}
+	for i := start; i < len(sizes); i++ {
+		if len(out.Parts) == pageSize {
+			out.IsTruncated = aws.Bool(true)
+			out.NextPartNumberMarker = aws.Int64(int64(i))
+			break
+		}
+
+		out.Parts = append(out.Parts, &s3.Part{PartNumber: aws.Int64(int64(i + 1)), Size: aws.Int64(sizes[i])})
+	}
+
+	return out, nil
+}
+
+// sliceSource is an s3svc.VersionSource that yields a fixed list of versions, then err.
+type sliceSource struct {
+	versions []*s3svc.Version
+	current  *s3svc.Version
+	err      error
+}
+
+func (s *sliceSource) Next() bool {
+	if len(s.versions) == 0 {
+		return false
+	}
+
+	s.current, s.versions = s.versions[0], s.versions[1:]
+	return true
+}
+
+func (s *sliceSource) Version() *s3svc.Version {
+	return s.current
+}
+
+func (s *sliceSource) Err() error {
+	if len(s.versions) > 0 {
+		return nil
+	}
+
+	return s.err
+}
+
+// drain reads every version left in source and returns them as "key@versionId".
+func drain(source s3svc.VersionSource) []string {
+	ids := []string{}
+	for source.Next() {
+		v := source.Version()
+		ids = append(ids, v.Key+"@"+v.VersionID)
+	}
+
+	return ids
+}
+
+// newFakeS3 returns an s3svcfakes.FakeAPI backed by the bucket.
+func newFakeS3(bucket *fakeBucket) *s3svcfakes.FakeAPI {
+	return &s3svcfakes.FakeAPI{
+		AbortMultipartUploadStub: bucket.AbortMultipartUpload,
+		DeleteObjectsStub:        bucket.DeleteObjects,
+		ListMultipartUploadsStub: bucket.ListMultipartUploads,
+		ListObjectVersionsStub:   bucket.ListObjectVersions,
+		ListPartsStub:            bucket.ListParts,
+	}
+}
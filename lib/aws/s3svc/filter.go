@@ -0,0 +1,212 @@
+package s3svc
+
+import (
+	"strings"
+	"time"
+)
+
+// Filter reports whether a version should be kept in a listing.
+type Filter func(*Version) bool
+
+// Noncurrent keeps the versions and delete markers that are no longer the latest for their key.
+func Noncurrent(v *Version) bool {
+	return !v.IsLatest
+}
+
+// Current keeps the latest version of each key that isn't deleted. Deleting the delete marker that hides
+// a deleted key would bring its last version back, so those are left alone.
+func Current(v *Version) bool {
+	return v.IsLatest && !v.IsDeleteMarker
+}
+
+// OlderThan keeps the versions and delete markers last modified before the cutoff. With sinceNoncurrent,
+// a noncurrent version's age is instead measured from when it was replaced, like the NoncurrentDays of a
+// lifecycle rule, so a version written long ago but overwritten yesterday is only a day old. Noncurrent
+// versions whose replacement wasn't listed, like the rest of a key a resumed listing starts in the
+// middle of, can't be told apart from those replaced a moment ago and are not kept.
+func OlderThan(cutoff time.Time, sinceNoncurrent bool) Filter {
+	return func(v *Version) bool {
+		if sinceNoncurrent && !v.IsLatest {
+			return !v.NoncurrentSince.IsZero() && v.NoncurrentSince.Before(cutoff)
+		}
+
+		return v.LastModified.Before(cutoff)
+	}
+}
+
+// UnderPrefixes keeps the versions and delete markers of keys that start with one of the prefixes,
+// for sources that aren't listed by prefix, like an inventory report.
+func UnderPrefixes(prefixes ...string) Filter {
+	prefixes = NormalizePrefixes(prefixes)
+
+	return func(v *Version) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(v.Key, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// FilterVersions returns a source that only yields the versions from source that pass every filter.
+func FilterVersions(source VersionSource, filters ...Filter) VersionSource {
+	return &filteredSource{VersionSource: source, filters: filters}
+}
+
+type filteredSource struct {
+	VersionSource
+	filters []Filter
+}
+
+func (s *filteredSource) Next() bool {
+next:
+	for s.VersionSource.Next() {
+		for _, filter := range s.filters {
+			if !filter(s.VersionSource.Version()) {
+				continue next
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// KeyFilter picks which versions of a single key to keep, given all of them in listing order.
+type KeyFilter func(versions []*Version) []*Version
+
+// StaleDeleteMarkers keeps delete markers that no longer hide anything: those that are not the latest
+// for their key, and latest ones with no object versions behind them. Removing a delete marker that
+// hides an older version would bring that version back, so those are never kept.
+func StaleDeleteMarkers(versions []*Version) []*Version {
+	hidesVersion := false
+	for _, v := range versions {
+		if !v.IsDeleteMarker {
+			hidesVersion = true
+			break
+		}
+	}
+
+	kept := []*Version{}
+	for _, v := range versions {
+		if v.IsDeleteMarker && (!v.IsLatest || !hidesVersion) {
+			kept = append(kept, v)
+		}
+	}
+
+	return kept
+}
+
+// KeepNewest returns a KeyFilter that keeps what has to go for each key to be left with only its n
+// newest object versions. The latest version or delete marker is never kept, a latest version counts
+// as one of the n. When a key's latest entry is a delete marker, its n newest versions are left
+// behind the marker so the object can still be restored. Older delete markers stay when they sit
+// between versions that stay, and go along with everything past the nth version otherwise.
+func KeepNewest(n int) KeyFilter {
+	return func(versions []*Version) []*Version {
+		stays := make([]bool, len(versions))
+		remaining := 0
+
+		// markers seen since the last version, they stay only if the next version does
+		markers := []int{}
+
+		for i, v := range versions {
+			switch {
+			case v.IsLatest:
+				stays[i] = true
+				if !v.IsDeleteMarker {
+					remaining++
+				}
+			case v.IsDeleteMarker:
+				markers = append(markers, i)
+			default:
+				if remaining < n {
+					stays[i] = true
+					remaining++
+					for _, m := range markers {
+						stays[m] = true
+					}
+				}
+				markers = markers[:0]
+			}
+		}
+
+		prune := []*Version{}
+		for i, v := range versions {
+			if !stays[i] {
+				prune = append(prune, v)
+			}
+		}
+
+		return prune
+	}
+}
+
+// FilterKeys returns a source that collects consecutive versions of the same key from source, which
+// a listing always returns together, and yields the ones filter keeps.
+func FilterKeys(source VersionSource, filter KeyFilter) VersionSource {
+	return &keyFilteredSource{source: source, filter: filter}
+}
+
+type keyFilteredSource struct {
+	source VersionSource
+	filter KeyFilter
+
+	// next is the first version of the following key, read while collecting the current one
+	next      *Version
+	exhausted bool
+
+	kept    []*Version
+	current *Version
+}
+
+func (s *keyFilteredSource) Next() bool {
+	for len(s.kept) == 0 {
+		group := s.readKey()
+		if len(group) == 0 {
+			return false
+		}
+
+		s.kept = s.filter(group)
+	}
+
+	s.current, s.kept = s.kept[0], s.kept[1:]
+	return true
+}
+
+// readKey returns every version of the next key in the source.
+func (s *keyFilteredSource) readKey() []*Version {
+	group := []*Version{}
+	if s.next != nil {
+		group = append(group, s.next)
+		s.next = nil
+	}
+
+	for !s.exhausted {
+		if !s.source.Next() {
+			s.exhausted = true
+			break
+		}
+
+		v := s.source.Version()
+		if len(group) > 0 && v.Key != group[0].Key {
+			s.next = v
+			break
+		}
+
+		group = append(group, v)
+	}
+
+	return group
+}
+
+func (s *keyFilteredSource) Version() *Version {
+	return s.current
+}
+
+func (s *keyFilteredSource) Err() error {
+	return s.source.Err()
+}
@@ -0,0 +1,230 @@
+package s3svc_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+)
+
+var _ = Describe("Filter", func() {
+	var versions []*s3svc.Version
+
+	BeforeEach(func() {
+		versions = []*s3svc.Version{
+			// a live object with history
+			{Key: "live", VersionID: "l2", IsLatest: true},
+			{Key: "live", VersionID: "l1"},
+			// a deleted object that still has versions behind its marker
+			{Key: "hidden", VersionID: "hdm", IsDeleteMarker: true, IsLatest: true},
+			{Key: "hidden", VersionID: "h1"},
+			// an expired object delete marker with nothing behind it
+			{Key: "orphan", VersionID: "odm2", IsDeleteMarker: true, IsLatest: true},
+			{Key: "orphan", VersionID: "odm1", IsDeleteMarker: true},
+			// a live object with an old delete marker in its history
+			{Key: "revived", VersionID: "r2", IsLatest: true},
+			{Key: "revived", VersionID: "rdm", IsDeleteMarker: true},
+			{Key: "revived", VersionID: "r1"},
+		}
+	})
+
+	Describe("FilterVersions", func() {
+		It("should keep only noncurrent versions and delete markers with Noncurrent", func() {
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: versions}, s3svc.Noncurrent))).To(Equal([]string{
+				"live@l1", "hidden@h1", "orphan@odm1", "revived@rdm", "revived@r1",
+			}))
+		})
+
+		It("should keep only the latest version of each key with Current", func() {
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: versions}, s3svc.Current))).To(Equal([]string{
+				"live@l2", "revived@r2",
+			}))
+		})
+
+		It("should leave the delete markers of deleted keys alone with Current", func() {
+			kept := drain(s3svc.FilterVersions(&sliceSource{versions: versions}, s3svc.Current))
+			Expect(kept).NotTo(ContainElement("hidden@hdm"))
+			Expect(kept).NotTo(ContainElement("orphan@odm2"))
+		})
+
+		It("should require every filter to pass", func() {
+			notRevived := func(v *s3svc.Version) bool { return v.Key != "revived" }
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: versions}, s3svc.Noncurrent, notRevived))).To(Equal([]string{
+				"live@l1", "hidden@h1", "orphan@odm1",
+			}))
+		})
+
+		It("should pass through the error of the source", func() {
+			source := s3svc.FilterVersions(&sliceSource{versions: versions, err: errors.New("fail")}, s3svc.Current)
+			drain(source)
+			Expect(source.Err()).To(MatchError("fail"))
+		})
+	})
+
+	Describe("OlderThan", func() {
+		var (
+			cutoff time.Time
+			aged   []*s3svc.Version
+		)
+
+		BeforeEach(func() {
+			cutoff = time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)
+			aged = []*s3svc.Version{
+				{Key: "new", VersionID: "n1", IsLatest: true, LastModified: cutoff.Add(time.Hour)},
+				{Key: "old", VersionID: "o1", IsLatest: true, LastModified: cutoff.Add(-time.Hour)},
+				// written long ago but only replaced after the cutoff
+				{Key: "replaced", VersionID: "r1", LastModified: cutoff.Add(-48 * time.Hour), NoncurrentSince: cutoff.Add(time.Hour)},
+				// written and replaced long ago
+				{Key: "stale", VersionID: "s1", LastModified: cutoff.Add(-48 * time.Hour), NoncurrentSince: cutoff.Add(-24 * time.Hour)},
+			}
+		})
+
+		It("should keep versions last modified before the cutoff", func() {
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: aged}, s3svc.OlderThan(cutoff, false)))).To(Equal([]string{
+				"old@o1", "replaced@r1", "stale@s1",
+			}))
+		})
+
+		It("should measure noncurrent versions from when they were replaced when asked to", func() {
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: aged}, s3svc.OlderThan(cutoff, true)))).To(Equal([]string{
+				"old@o1", "stale@s1",
+			}))
+		})
+
+		It("should not keep noncurrent versions when it is unknown when they were replaced", func() {
+			aged = append(aged, &s3svc.Version{Key: "resumed", VersionID: "u1", LastModified: cutoff.Add(-48 * time.Hour)})
+
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: aged}, s3svc.OlderThan(cutoff, true)))).To(Equal([]string{
+				"old@o1", "stale@s1",
+			}))
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: aged}, s3svc.OlderThan(cutoff, false)))).To(ContainElement("resumed@u1"))
+		})
+	})
+
+	Describe("UnderPrefixes", func() {
+		It("should keep the keys under any of the prefixes", func() {
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: versions}, s3svc.UnderPrefixes("li", "orphan")))).To(Equal([]string{
+				"live@l2", "live@l1", "orphan@odm2", "orphan@odm1",
+			}))
+		})
+
+		It("should keep everything without prefixes", func() {
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: versions}, s3svc.UnderPrefixes()))).To(HaveLen(9))
+		})
+	})
+
+	Describe("FilterKeys", func() {
+		It("should keep only delete markers that do not hide a version with StaleDeleteMarkers", func() {
+			Expect(drain(s3svc.FilterKeys(&sliceSource{versions: versions}, s3svc.StaleDeleteMarkers))).To(Equal([]string{
+				"orphan@odm2", "orphan@odm1", "revived@rdm",
+			}))
+		})
+
+		It("should keep only what is past the newest version of each key with KeepNewest(1)", func() {
+			Expect(drain(s3svc.FilterKeys(&sliceSource{versions: versions}, s3svc.KeepNewest(1)))).To(Equal([]string{
+				"live@l1", "orphan@odm1", "revived@rdm", "revived@r1",
+			}))
+		})
+
+		It("should leave delete markers between the versions that stay with KeepNewest", func() {
+			Expect(drain(s3svc.FilterKeys(&sliceSource{versions: versions}, s3svc.KeepNewest(2)))).To(Equal([]string{
+				"orphan@odm1",
+			}))
+		})
+
+		It("should never pick the latest entry with KeepNewest", func() {
+			history := []*s3svc.Version{
+				{Key: "k", VersionID: "dm", IsDeleteMarker: true, IsLatest: true},
+				{Key: "k", VersionID: "v4"},
+				{Key: "k", VersionID: "v3"},
+				{Key: "k", VersionID: "old-dm", IsDeleteMarker: true},
+				{Key: "k", VersionID: "v2"},
+				{Key: "k", VersionID: "v1"},
+			}
+
+			Expect(drain(s3svc.FilterKeys(&sliceSource{versions: history}, s3svc.KeepNewest(2)))).To(Equal([]string{
+				"k@old-dm", "k@v2", "k@v1",
+			}))
+			Expect(drain(s3svc.FilterKeys(&sliceSource{versions: history}, s3svc.KeepNewest(0)))).To(Equal([]string{
+				"k@v4", "k@v3", "k@old-dm", "k@v2", "k@v1",
+			}))
+		})
+
+		It("should hand the filter every version of one key at a time", func() {
+			groups := [][]string{}
+			collect := func(group []*s3svc.Version) []*s3svc.Version {
+				ids := []string{}
+				for _, v := range group {
+					ids = append(ids, v.VersionID)
+				}
+				groups = append(groups, ids)
+				return nil
+			}
+
+			Expect(drain(s3svc.FilterKeys(&sliceSource{versions: versions}, collect))).To(BeEmpty())
+			Expect(groups).To(Equal([][]string{
+				{"l2", "l1"}, {"hdm", "h1"}, {"odm2", "odm1"}, {"r2", "rdm", "r1"},
+			}))
+		})
+
+		It("should yield nothing for an empty source", func() {
+			Expect(drain(s3svc.FilterKeys(&sliceSource{}, s3svc.StaleDeleteMarkers))).To(BeEmpty())
+		})
+
+		It("should pass through the error of the source", func() {
+			source := s3svc.FilterKeys(&sliceSource{versions: versions, err: errors.New("fail")}, s3svc.StaleDeleteMarkers)
+			drain(source)
+			Expect(source.Err()).To(MatchError("fail"))
+		})
+	})
+
+	Describe("CountVersions", func() {
+		It("should count everything when below the limit", func() {
+			count, more, err := s3svc.CountVersions(&sliceSource{versions: versions}, 100)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(9))
+			Expect(more).To(BeFalse())
+		})
+
+		It("should stop at the limit and report that there is more", func() {
+			count, more, err := s3svc.CountVersions(&sliceSource{versions: versions}, 4)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(4))
+			Expect(more).To(BeTrue())
+		})
+
+		It("should not report more when the source ends exactly at the limit", func() {
+			count, more, err := s3svc.CountVersions(&sliceSource{versions: versions}, 9)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(9))
+			Expect(more).To(BeFalse())
+		})
+
+		It("should return the error of the source", func() {
+			_, _, err := s3svc.CountVersions(&sliceSource{versions: versions, err: errors.New("fail")}, 100)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("DeleteVersions with a filtered listing", func() {
+		It("should only delete what the filters keep", func() {
+			fake := &fakeBucket{pageSize: 3}
+			fake.addVersion("a", "a2")
+			fake.addVersion("a", "a1")
+			fake.addDeleteMarker("b", "bdm")
+			fake.addVersion("b", "b1")
+			fake.addVersion("c", "c1")
+
+			fakeS3 := newFakeS3(fake)
+			client := s3svc.NewClient(fakeS3, false)
+
+			result, err := client.DeleteVersions("bucket", s3svc.FilterVersions(client.NewVersionIterator("bucket"), s3svc.Noncurrent))
+			Expect(err).To(BeNil())
+			Expect(result.Deleted).To(Equal(2))
+			Expect(fake.remaining()).To(ConsistOf("a@a2", "b@bdm", "c@c1"))
+		})
+	})
+})
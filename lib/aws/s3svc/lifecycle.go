@@ -0,0 +1,117 @@
+package s3svc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// ExpireRulePrefix starts the IDs of the lifecycle rules ExpireRules returns, so they can be told
+// apart from rules the bucket had before.
+const ExpireRulePrefix = "s3helper-expire-"
+
+// ExpireRules returns lifecycle rules that have S3 expire everything under the prefixes, or in the
+// whole bucket without any: current versions a day after they were written, noncurrent versions a
+// day after they were replaced, delete markers once no version is left behind them, and multipart
+// uploads a day after they were started. S3 applies lifecycle rules in the background, usually
+// within a day or two, without the requests and request costs of deleting object by object.
+func ExpireRules(prefixes ...string) []*s3.LifecycleRule {
+	// without prefixes NormalizePrefixes gives the empty one, which covers the whole bucket
+	rules := []*s3.LifecycleRule{}
+	for i, prefix := range NormalizePrefixes(prefixes) {
+		id := fmt.Sprintf("%s%03d", ExpireRulePrefix, i)
+
+		// S3 refuses a rule that expires both current versions after a number of days and delete
+		// markers that expired, so the delete markers get a rule of their own
+		rules = append(rules,
+			&s3.LifecycleRule{
+				ID:                             aws.String(id + "-versions"),
+				Filter:                         &s3.LifecycleRuleFilter{Prefix: aws.String(prefix)},
+				Status:                         aws.String(s3.ExpirationStatusEnabled),
+				Expiration:                     &s3.LifecycleExpiration{Days: aws.Int64(1)},
+				NoncurrentVersionExpiration:    &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(1)},
+				AbortIncompleteMultipartUpload: &s3.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int64(1)},
+			},
+			&s3.LifecycleRule{
+				ID:         aws.String(id + "-delete-markers"),
+				Filter:     &s3.LifecycleRuleFilter{Prefix: aws.String(prefix)},
+				Status:     aws.String(s3.ExpirationStatusEnabled),
+				Expiration: &s3.LifecycleExpiration{ExpiredObjectDeleteMarker: aws.Bool(true)},
+			},
+		)
+	}
+
+	return rules
+}
+
+// MergeExpireRules returns the rules with the ExpireRules for the prefixes added, so that keys outside
+// the prefixes keep the lifecycle they had. S3 refuses configurations that mix rules with a filter and
+// rules with the older top-level prefix, so those are given the same prefix as a filter instead.
+func MergeExpireRules(rules []*s3.LifecycleRule, prefixes ...string) []*s3.LifecycleRule {
+	merged := []*s3.LifecycleRule{}
+	for _, rule := range rules {
+		if rule.Filter == nil {
+			converted := *rule
+			converted.Filter = &s3.LifecycleRuleFilter{Prefix: aws.String(aws.StringValue(rule.Prefix))}
+			converted.Prefix = nil
+			rule = &converted
+		}
+
+		merged = append(merged, rule)
+	}
+
+	return append(merged, ExpireRules(prefixes...)...)
+}
+
+// HasExpireRules reports whether any of the rules is one ExpireRules returns.
+func HasExpireRules(rules []*s3.LifecycleRule) bool {
+	for _, rule := range rules {
+		if strings.HasPrefix(aws.StringValue(rule.ID), ExpireRulePrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LifecycleRules returns the lifecycle rules of the specified s3 bucket, or none when the bucket has
+// no lifecycle configuration.
+func (c *Client) LifecycleRules(bucket string) ([]*s3.LifecycleRule, error) {
+	resp, err := c.s3api.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchLifecycleConfiguration" {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "package: s3svc => method: LifecycleRules => method call s3api.GetBucketLifecycleConfiguration failed\n")
+	}
+
+	return resp.Rules, nil
+}
+
+// PutLifecycleRules replaces the lifecycle configuration of the specified s3 bucket with the rules,
+// and removes it when there are none.
+func (c *Client) PutLifecycleRules(bucket string, rules []*s3.LifecycleRule) error {
+	if len(rules) == 0 {
+		if _, err := c.s3api.DeleteBucketLifecycle(&s3.DeleteBucketLifecycleInput{Bucket: aws.String(bucket)}); err != nil {
+			return errors.Wrap(err, "package: s3svc => method: PutLifecycleRules => method call s3api.DeleteBucketLifecycle failed\n")
+		}
+
+		return nil
+	}
+
+	input := &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+	}
+
+	if _, err := c.s3api.PutBucketLifecycleConfiguration(input); err != nil {
+		return errors.Wrap(err, "package: s3svc => method: PutLifecycleRules => method call s3api.PutBucketLifecycleConfiguration failed\n")
+	}
+
+	return nil
+}
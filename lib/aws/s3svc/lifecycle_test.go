@@ -0,0 +1,136 @@
+package s3svc_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc/s3svcfakes"
+)
+
+var _ = Describe("Lifecycle", func() {
+	var (
+		fakeS3 *s3svcfakes.FakeAPI
+		client *s3svc.Client
+	)
+
+	BeforeEach(func() {
+		fakeS3 = &s3svcfakes.FakeAPI{}
+		client = s3svc.NewClient(fakeS3, false)
+	})
+
+	Describe("ExpireRules", func() {
+		It("should expire the whole bucket without prefixes", func() {
+			rules := s3svc.ExpireRules()
+			Expect(rules).To(HaveLen(2))
+
+			versions, markers := rules[0], rules[1]
+			Expect(aws.StringValue(versions.ID)).To(Equal("s3helper-expire-000-versions"))
+			Expect(aws.StringValue(versions.Filter.Prefix)).To(Equal(""))
+			Expect(aws.StringValue(versions.Status)).To(Equal("Enabled"))
+			Expect(aws.Int64Value(versions.Expiration.Days)).To(Equal(int64(1)))
+			Expect(versions.Expiration.ExpiredObjectDeleteMarker).To(BeNil())
+			Expect(aws.Int64Value(versions.NoncurrentVersionExpiration.NoncurrentDays)).To(Equal(int64(1)))
+			Expect(aws.Int64Value(versions.AbortIncompleteMultipartUpload.DaysAfterInitiation)).To(Equal(int64(1)))
+
+			Expect(aws.StringValue(markers.ID)).To(Equal("s3helper-expire-000-delete-markers"))
+			Expect(markers.Expiration.Days).To(BeNil())
+			Expect(aws.BoolValue(markers.Expiration.ExpiredObjectDeleteMarker)).To(BeTrue())
+		})
+
+		It("should add rules for every prefix not covered by another", func() {
+			rules := s3svc.ExpireRules("logs/", "tmp/", "logs/2018/")
+			Expect(rules).To(HaveLen(4))
+			Expect(aws.StringValue(rules[0].Filter.Prefix)).To(Equal("logs/"))
+			Expect(aws.StringValue(rules[2].Filter.Prefix)).To(Equal("tmp/"))
+			Expect(aws.StringValue(rules[2].ID)).To(Equal("s3helper-expire-001-versions"))
+			Expect(s3svc.HasExpireRules(rules)).To(BeTrue())
+		})
+	})
+
+	Describe("MergeExpireRules", func() {
+		It("should keep the rules of the bucket next to the expire rules", func() {
+			archive := &s3.LifecycleRule{ID: aws.String("archive"), Filter: &s3.LifecycleRuleFilter{Prefix: aws.String("data/")}, Status: aws.String("Enabled")}
+
+			rules := s3svc.MergeExpireRules([]*s3.LifecycleRule{archive}, "tmp/")
+			Expect(rules).To(HaveLen(3))
+			Expect(rules[0]).To(Equal(archive))
+			Expect(aws.StringValue(rules[1].ID)).To(Equal("s3helper-expire-000-versions"))
+			Expect(aws.StringValue(rules[1].Filter.Prefix)).To(Equal("tmp/"))
+		})
+
+		It("should give rules with a top-level prefix a filter instead", func() {
+			legacy := &s3.LifecycleRule{ID: aws.String("legacy"), Prefix: aws.String("old/"), Status: aws.String("Enabled")}
+
+			rules := s3svc.MergeExpireRules([]*s3.LifecycleRule{legacy}, "tmp/")
+			Expect(rules[0].Prefix).To(BeNil())
+			Expect(aws.StringValue(rules[0].Filter.Prefix)).To(Equal("old/"))
+			Expect(aws.StringValue(legacy.Prefix)).To(Equal("old/"))
+		})
+	})
+
+	Describe("HasExpireRules", func() {
+		It("should not count rules from elsewhere", func() {
+			Expect(s3svc.HasExpireRules(nil)).To(BeFalse())
+			Expect(s3svc.HasExpireRules([]*s3.LifecycleRule{{ID: aws.String("archive")}})).To(BeFalse())
+		})
+	})
+
+	Describe("LifecycleRules", func() {
+		It("should return the rules of the bucket", func() {
+			rules := []*s3.LifecycleRule{{ID: aws.String("archive")}}
+			fakeS3.GetBucketLifecycleConfigurationReturns(&s3.GetBucketLifecycleConfigurationOutput{Rules: rules}, nil)
+
+			got, err := client.LifecycleRules("bucket")
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(rules))
+			Expect(aws.StringValue(fakeS3.GetBucketLifecycleConfigurationArgsForCall(0).Bucket)).To(Equal("bucket"))
+		})
+
+		It("should return no rules when the bucket has no configuration", func() {
+			fakeS3.GetBucketLifecycleConfigurationReturns(nil, awserr.New("NoSuchLifecycleConfiguration", "The lifecycle configuration does not exist", nil))
+
+			got, err := client.LifecycleRules("bucket")
+			Expect(err).To(BeNil())
+			Expect(got).To(BeEmpty())
+		})
+
+		It("should return other errors", func() {
+			fakeS3.GetBucketLifecycleConfigurationReturns(nil, errors.New("AccessDenied"))
+
+			_, err := client.LifecycleRules("bucket")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("PutLifecycleRules", func() {
+		It("should replace the configuration with the rules", func() {
+			rules := s3svc.ExpireRules()
+			Expect(client.PutLifecycleRules("bucket", rules)).To(Succeed())
+
+			Expect(fakeS3.PutBucketLifecycleConfigurationCallCount()).To(Equal(1))
+			input := fakeS3.PutBucketLifecycleConfigurationArgsForCall(0)
+			Expect(aws.StringValue(input.Bucket)).To(Equal("bucket"))
+			Expect(input.LifecycleConfiguration.Rules).To(Equal(rules))
+			Expect(fakeS3.DeleteBucketLifecycleCallCount()).To(Equal(0))
+		})
+
+		It("should remove the configuration without rules", func() {
+			Expect(client.PutLifecycleRules("bucket", nil)).To(Succeed())
+
+			Expect(fakeS3.DeleteBucketLifecycleCallCount()).To(Equal(1))
+			Expect(fakeS3.PutBucketLifecycleConfigurationCallCount()).To(Equal(0))
+		})
+
+		It("should return errors", func() {
+			fakeS3.PutBucketLifecycleConfigurationReturns(nil, errors.New("MalformedXML"))
+			Expect(client.PutLifecycleRules("bucket", s3svc.ExpireRules())).NotTo(Succeed())
+		})
+	})
+})
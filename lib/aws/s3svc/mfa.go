@@ -0,0 +1,69 @@
+package s3svc
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+)
+
+// mfa hands out the value for the MFA field of DeleteObjects requests on buckets with MFA Delete,
+// the device serial number and a current token separated by a space. Tokens come from a function,
+// usually a prompt, and are asked for again once they are older than the window or S3 rejects them.
+type mfa struct {
+	mu     sync.Mutex
+	serial string
+	window time.Duration
+	token  func() (string, error)
+
+	value  string
+	issued time.Time
+}
+
+// WithMFA makes the client send an MFA token with every DeleteObjects request, as buckets with MFA
+// Delete require. token is called for a new code whenever the last one is older than window, 30
+// seconds for the usual TOTP devices, or S3 turned it down. Only one call to token runs at a time,
+// concurrent requests wait for it and share the code.
+func WithMFA(serial string, window time.Duration, token func() (string, error)) func(*Client) {
+	return func(c *Client) {
+		c.mfa = &mfa{serial: serial, window: window, token: token}
+	}
+}
+
+// current returns the MFA value to send, asking for a new token if the last one has expired.
+func (m *mfa) current() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.value != "" && time.Since(m.issued) < m.window {
+		return m.value, nil
+	}
+
+	token, err := m.token()
+	if err != nil {
+		return "", errors.Wrap(err, "package: s3svc => method: mfa.current => func call token failed\n")
+	}
+
+	m.value = m.serial + " " + strings.TrimSpace(token)
+	m.issued = time.Now()
+
+	return m.value, nil
+}
+
+// reject drops value after S3 turned it down, unless another request already got a new one.
+func (m *mfa) reject(value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.value == value {
+		m.value = ""
+	}
+}
+
+// isMFAError reports whether S3 refused a request because of a missing, invalid, or expired MFA token.
+func isMFAError(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == "AccessDenied" && strings.Contains(strings.ToLower(aerr.Message()), "mfa")
+}
@@ -0,0 +1,127 @@
+package s3svc_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc/s3svcfakes"
+)
+
+var _ = Describe("MFA Delete", func() {
+	var (
+		fake     *fakeBucket
+		fakeS3   *s3svcfakes.FakeAPI
+		client   *s3svc.Client
+		window   time.Duration
+		tokens   int
+		tokenErr error
+	)
+
+	BeforeEach(func() {
+		fake = &fakeBucket{}
+		for i := 0; i < 4; i++ {
+			fake.addVersion(fmt.Sprintf("key-%d", i), fmt.Sprintf("v%d", i))
+		}
+		fakeS3 = newFakeS3(fake)
+
+		window = time.Minute
+		tokens = 0
+		tokenErr = nil
+	})
+
+	JustBeforeEach(func() {
+		token := func() (string, error) {
+			tokens++
+			return fmt.Sprintf(" 00000%d\n", tokens), tokenErr
+		}
+		client = s3svc.NewClient(fakeS3, false, s3svc.WithBatchSize(1), s3svc.WithMFA("arn:aws:iam::123456789012:mfa/me", window, token))
+	})
+
+	It("should send the serial and token with every DeleteObjects request", func() {
+		_, err := client.DeleteBucketContents("bucket")
+		Expect(err).To(BeNil())
+		Expect(fake.remaining()).To(BeEmpty())
+
+		Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(4))
+		for i := 0; i < 4; i++ {
+			Expect(aws.StringValue(fakeS3.DeleteObjectsArgsForCall(i).MFA)).To(Equal("arn:aws:iam::123456789012:mfa/me 000001"))
+		}
+		Expect(tokens).To(Equal(1))
+	})
+
+	Context("when the token window runs out during the run", func() {
+		BeforeEach(func() {
+			window = 10 * time.Millisecond
+			stub := fakeS3.DeleteObjectsStub
+			fakeS3.DeleteObjectsStub = func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				time.Sleep(6 * time.Millisecond)
+				return stub(input)
+			}
+		})
+
+		It("should ask for a new token", func() {
+			_, err := client.DeleteBucketContents("bucket")
+			Expect(err).To(BeNil())
+			Expect(tokens).To(BeNumerically(">=", 2))
+			Expect(aws.StringValue(fakeS3.DeleteObjectsArgsForCall(3).MFA)).NotTo(HaveSuffix(" 000001"))
+		})
+	})
+
+	Context("when S3 rejects the token", func() {
+		BeforeEach(func() {
+			stub := fakeS3.DeleteObjectsStub
+			fakeS3.DeleteObjectsStub = func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				if fakeS3.DeleteObjectsCallCount() == 1 {
+					return nil, awserr.New("AccessDenied", "Invalid MFA token", nil)
+				}
+				return stub(input)
+			}
+		})
+
+		It("should ask for a new token and send the request again", func() {
+			_, err := client.DeleteBucketContents("bucket")
+			Expect(err).To(BeNil())
+			Expect(fake.remaining()).To(BeEmpty())
+			Expect(tokens).To(Equal(2))
+			Expect(aws.StringValue(fakeS3.DeleteObjectsArgsForCall(1).MFA)).To(HaveSuffix(" 000002"))
+		})
+	})
+
+	Context("when S3 keeps rejecting the token", func() {
+		BeforeEach(func() {
+			fake = &fakeBucket{}
+			fake.addVersion("key", "v")
+			fakeS3 = newFakeS3(fake)
+			fakeS3.DeleteObjectsStub = nil
+			fakeS3.DeleteObjectsReturns(nil, awserr.New("AccessDenied", "Mfa Authentication must be used for this request", nil))
+		})
+
+		It("should give up after one new token", func() {
+			_, err := client.DeleteBucketContents("bucket")
+			Expect(err).NotTo(BeNil())
+			Expect(tokens).To(Equal(2))
+			Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when no token can be read", func() {
+		BeforeEach(func() {
+			tokenErr = errors.New("EOF")
+		})
+
+		It("should not send anything", func() {
+			_, err := client.DeleteBucketContents("bucket")
+			Expect(err).NotTo(BeNil())
+			Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,238 @@
+package s3svc
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+)
+
+// Lock is the Object Lock protection of a single object version.
+type Lock struct {
+	// Mode is LockModeGovernance or LockModeCompliance, or empty without retention.
+	Mode        string
+	RetainUntil time.Time
+	LegalHold   bool
+}
+
+// Retained reports whether the version's retention period still runs at now.
+func (l *Lock) Retained(now time.Time) bool {
+	return l.Mode != "" && l.RetainUntil.After(now)
+}
+
+// Deletable reports whether the version can be deleted at now. A legal hold or compliance-mode
+// retention can't be lifted by a delete request, governance-mode retention can with bypassGovernance.
+func (l *Lock) Deletable(now time.Time, bypassGovernance bool) bool {
+	if l.LegalHold {
+		return false
+	}
+
+	return !l.Retained(now) || (l.Mode == LockModeGovernance && bypassGovernance)
+}
+
+// LockCount tallies the versions under one retention mode and when the last of them runs out.
+type LockCount struct {
+	Versions int
+	Until    time.Time
+}
+
+// LockReport describes the Object Lock protection of the versions in a listing.
+type LockReport struct {
+	Scanned    int
+	Governance LockCount
+	Compliance LockCount
+	LegalHold  int
+
+	// Locked holds the lock of every version that has retention or a legal hold at the time of the scan.
+	Locked map[string]*Lock
+
+	scanned time.Time
+}
+
+// Lock returns the lock of a version, or nil if it has none.
+func (r *LockReport) Lock(v *Version) *Lock {
+	return r.Locked[v.Key+"\x00"+v.VersionID]
+}
+
+// Undeletable counts the versions a delete request can't remove.
+func (r *LockReport) Undeletable(bypassGovernance bool) int {
+	count := 0
+	for _, lock := range r.Locked {
+		if !lock.Deletable(r.scanned, bypassGovernance) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Deletable returns a filter that keeps the versions a delete request can remove, so versions under
+// a legal hold or compliance-mode retention are skipped rather than failing on every run.
+func (r *LockReport) Deletable(bypassGovernance bool) Filter {
+	return func(v *Version) bool {
+		lock := r.Lock(v)
+		return lock == nil || lock.Deletable(r.scanned, bypassGovernance)
+	}
+}
+
+// WithLockAPI sets the client used for Object Lock calls, which the vendored SDK's s3 client lacks.
+func WithLockAPI(api LockAPI) func(*Client) {
+	return func(c *Client) {
+		c.lockAPI = api
+	}
+}
+
+// LockConfiguration returns the Object Lock configuration of the specified s3 bucket, or nil if
+// Object Lock is not enabled on it.
+func (c *Client) LockConfiguration(bucket string) (*ObjectLockConfiguration, error) {
+	var resp *GetObjectLockConfigurationOutput
+
+	err := c.throttled(func() (err error) {
+		resp, err = c.lockAPI.GetObjectLockConfiguration(&GetObjectLockConfigurationInput{Bucket: aws.String(bucket)})
+		return err
+	})
+	if isErrorCode(err, errCodeNoLockConfiguration) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "package: s3svc => method: LockConfiguration => method call lockAPI.GetObjectLockConfiguration failed\n")
+	}
+
+	if resp.ObjectLockConfiguration == nil || aws.StringValue(resp.ObjectLockConfiguration.ObjectLockEnabled) != "Enabled" {
+		return nil, nil
+	}
+
+	return resp.ObjectLockConfiguration, nil
+}
+
+// ScanLocks looks up the retention and legal hold of every object version source yields, running as
+// many lookups in parallel as the client's concurrency allows. Delete markers can't be locked and
+// are only counted.
+func (c *Client) ScanLocks(bucket string, source VersionSource) (*LockReport, error) {
+	report := &LockReport{Locked: map[string]*Lock{}, scanned: time.Now()}
+
+	batch := make([]*Version, 0, c.batchSize)
+	scan := func() error {
+		locks := make([]*Lock, len(batch))
+		errs := make([]error, len(batch))
+
+		c.each(len(batch), func(i int) {
+			if !batch[i].IsDeleteMarker {
+				locks[i], errs[i] = c.lock(bucket, batch[i])
+			}
+		})
+
+		for i, v := range batch {
+			if errs[i] != nil {
+				return errs[i]
+			}
+
+			report.add(v, locks[i])
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	for source.Next() {
+		batch = append(batch, source.Version())
+		if len(batch) < c.batchSize {
+			continue
+		}
+
+		if err := scan(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := source.Err(); err != nil {
+		return nil, errors.Wrap(err, "package: s3svc => method: ScanLocks => method call s3svc.VersionSource.Next failed\n")
+	}
+
+	if err := scan(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (r *LockReport) add(v *Version, lock *Lock) {
+	r.Scanned++
+
+	if lock == nil {
+		return
+	}
+
+	if lock.LegalHold {
+		r.LegalHold++
+	}
+
+	if lock.Retained(r.scanned) {
+		count := &r.Governance
+		if lock.Mode == LockModeCompliance {
+			count = &r.Compliance
+		}
+
+		count.Versions++
+		if lock.RetainUntil.After(count.Until) {
+			count.Until = lock.RetainUntil
+		}
+	}
+
+	if lock.LegalHold || lock.Retained(r.scanned) {
+		r.Locked[v.Key+"\x00"+v.VersionID] = lock
+	}
+}
+
+// lock returns the retention and legal hold of a single version, or nil if it has neither.
+func (c *Client) lock(bucket string, v *Version) (*Lock, error) {
+	lock := &Lock{}
+
+	var retention *GetObjectRetentionOutput
+	err := c.throttled(func() (err error) {
+		retention, err = c.lockAPI.GetObjectRetention(&GetObjectRetentionInput{
+			Bucket:    aws.String(bucket),
+			Key:       aws.String(v.Key),
+			VersionId: aws.String(v.VersionID),
+		})
+		return err
+	})
+	switch {
+	case isErrorCode(err, errCodeNoObjectLockConfiguration):
+	case err != nil:
+		return nil, errors.Wrapf(err, "package: s3svc => method: ScanLocks => method call lockAPI.GetObjectRetention failed for %s\n", v.Key)
+	case retention.Retention != nil:
+		lock.Mode = aws.StringValue(retention.Retention.Mode)
+		lock.RetainUntil = aws.TimeValue(retention.Retention.RetainUntilDate)
+	}
+
+	var legalHold *GetObjectLegalHoldOutput
+	err = c.throttled(func() (err error) {
+		legalHold, err = c.lockAPI.GetObjectLegalHold(&GetObjectLegalHoldInput{
+			Bucket:    aws.String(bucket),
+			Key:       aws.String(v.Key),
+			VersionId: aws.String(v.VersionID),
+		})
+		return err
+	})
+	switch {
+	case isErrorCode(err, errCodeNoObjectLockConfiguration):
+	case err != nil:
+		return nil, errors.Wrapf(err, "package: s3svc => method: ScanLocks => method call lockAPI.GetObjectLegalHold failed for %s\n", v.Key)
+	case legalHold.LegalHold != nil:
+		lock.LegalHold = aws.StringValue(legalHold.LegalHold.Status) == "ON"
+	}
+
+	if lock.Mode == "" && !lock.LegalHold {
+		return nil, nil
+	}
+
+	return lock, nil
+}
+
+// isErrorCode reports whether err is an aws error with the given code.
+func isErrorCode(err error, code string) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == code
+}
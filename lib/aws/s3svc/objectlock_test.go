@@ -0,0 +1,152 @@
+package s3svc_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc/s3svcfakes"
+)
+
+var _ = Describe("Object Lock", func() {
+	var (
+		fakeLock *s3svcfakes.FakeLockAPI
+		client   *s3svc.Client
+
+		notFound = func(code string) error {
+			return awserr.NewRequestFailure(awserr.New(code, "not found", nil), 404, "request-id")
+		}
+	)
+
+	BeforeEach(func() {
+		fakeLock = &s3svcfakes.FakeLockAPI{}
+		client = s3svc.NewClient(&s3svcfakes.FakeAPI{}, false, s3svc.WithLockAPI(fakeLock), s3svc.WithConcurrency(3), s3svc.WithBatchSize(2))
+	})
+
+	Describe("LockConfiguration", func() {
+		It("should return nil when the bucket has no Object Lock configuration", func() {
+			fakeLock.GetObjectLockConfigurationReturns(nil, notFound("ObjectLockConfigurationNotFoundError"))
+
+			config, err := client.LockConfiguration("bucket")
+			Expect(err).To(BeNil())
+			Expect(config).To(BeNil())
+		})
+
+		It("should return the configuration when Object Lock is enabled", func() {
+			fakeLock.GetObjectLockConfigurationReturns(&s3svc.GetObjectLockConfigurationOutput{
+				ObjectLockConfiguration: &s3svc.ObjectLockConfiguration{
+					ObjectLockEnabled: aws.String("Enabled"),
+					Rule: &s3svc.ObjectLockRule{
+						DefaultRetention: &s3svc.DefaultRetention{Mode: aws.String("GOVERNANCE"), Days: aws.Int64(30)},
+					},
+				},
+			}, nil)
+
+			config, err := client.LockConfiguration("bucket")
+			Expect(err).To(BeNil())
+			Expect(aws.Int64Value(config.Rule.DefaultRetention.Days)).To(Equal(int64(30)))
+			Expect(aws.StringValue(fakeLock.GetObjectLockConfigurationArgsForCall(0).Bucket)).To(Equal("bucket"))
+		})
+
+		It("should return other errors", func() {
+			fakeLock.GetObjectLockConfigurationReturns(nil, errors.New("AccessDenied"))
+
+			_, err := client.LockConfiguration("bucket")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("ScanLocks", func() {
+		var (
+			versions []*s3svc.Version
+			later    = time.Now().Add(24 * time.Hour)
+			latest   = time.Now().Add(48 * time.Hour)
+		)
+
+		BeforeEach(func() {
+			versions = []*s3svc.Version{
+				{Key: "governed", VersionID: "g1"},
+				{Key: "complied", VersionID: "c1"},
+				{Key: "held", VersionID: "h1"},
+				{Key: "expired", VersionID: "e1"},
+				{Key: "free", VersionID: "f1"},
+				{Key: "marker", VersionID: "m1", IsDeleteMarker: true},
+			}
+
+			retentions := map[string]*s3svc.ObjectLockRetention{
+				"governed": {Mode: aws.String("GOVERNANCE"), RetainUntilDate: aws.Time(later)},
+				"complied": {Mode: aws.String("COMPLIANCE"), RetainUntilDate: aws.Time(latest)},
+				"expired":  {Mode: aws.String("GOVERNANCE"), RetainUntilDate: aws.Time(time.Now().Add(-time.Hour))},
+			}
+			fakeLock.GetObjectRetentionStub = func(input *s3svc.GetObjectRetentionInput) (*s3svc.GetObjectRetentionOutput, error) {
+				retention, ok := retentions[*input.Key]
+				if !ok {
+					return nil, notFound("NoSuchObjectLockConfiguration")
+				}
+				return &s3svc.GetObjectRetentionOutput{Retention: retention}, nil
+			}
+
+			fakeLock.GetObjectLegalHoldStub = func(input *s3svc.GetObjectLegalHoldInput) (*s3svc.GetObjectLegalHoldOutput, error) {
+				switch *input.Key {
+				case "held":
+					return &s3svc.GetObjectLegalHoldOutput{LegalHold: &s3svc.ObjectLockLegalHold{Status: aws.String("ON")}}, nil
+				case "governed":
+					return &s3svc.GetObjectLegalHoldOutput{LegalHold: &s3svc.ObjectLockLegalHold{Status: aws.String("OFF")}}, nil
+				}
+				return nil, notFound("NoSuchObjectLockConfiguration")
+			}
+		})
+
+		It("should count the versions under retention and legal hold", func() {
+			report, err := client.ScanLocks("bucket", &sliceSource{versions: versions})
+			Expect(err).To(BeNil())
+			Expect(report.Scanned).To(Equal(6))
+			Expect(report.Governance).To(Equal(s3svc.LockCount{Versions: 1, Until: later}))
+			Expect(report.Compliance).To(Equal(s3svc.LockCount{Versions: 1, Until: latest}))
+			Expect(report.LegalHold).To(Equal(1))
+			Expect(report.Locked).To(HaveLen(3))
+			Expect(report.Lock(versions[4])).To(BeNil())
+		})
+
+		It("should not look up delete markers", func() {
+			_, err := client.ScanLocks("bucket", &sliceSource{versions: versions})
+			Expect(err).To(BeNil())
+			Expect(fakeLock.GetObjectRetentionCallCount()).To(Equal(5))
+			Expect(fakeLock.GetObjectLegalHoldCallCount()).To(Equal(5))
+		})
+
+		It("should only let governance-mode versions through when bypassing governance", func() {
+			report, err := client.ScanLocks("bucket", &sliceSource{versions: versions})
+			Expect(err).To(BeNil())
+
+			Expect(report.Undeletable(false)).To(Equal(3))
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: versions}, report.Deletable(false)))).To(Equal([]string{
+				"expired@e1", "free@f1", "marker@m1",
+			}))
+
+			Expect(report.Undeletable(true)).To(Equal(2))
+			Expect(drain(s3svc.FilterVersions(&sliceSource{versions: versions}, report.Deletable(true)))).To(Equal([]string{
+				"governed@g1", "expired@e1", "free@f1", "marker@m1",
+			}))
+		})
+
+		It("should return an error when a lookup fails", func() {
+			fakeLock.GetObjectLegalHoldStub = nil
+			fakeLock.GetObjectLegalHoldReturns(nil, errors.New("AccessDenied"))
+
+			_, err := client.ScanLocks("bucket", &sliceSource{versions: versions})
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should return the error of the source", func() {
+			_, err := client.ScanLocks("bucket", &sliceSource{versions: versions, err: errors.New("fail")})
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package s3svc
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PlanCount tallies the versions, delete markers, and bytes in part of a bucket.
+type PlanCount struct {
+	Versions      int64
+	DeleteMarkers int64
+	Bytes         int64
+}
+
+// Plan describes everything DeleteBucketContents would remove from a bucket.
+type Plan struct {
+	PlanCount
+
+	// Prefixes holds a count per top-level prefix, i.e. the key up to and including the first "/".
+	// Keys without a "/" are counted under "".
+	Prefixes map[string]*PlanCount
+}
+
+// PlanVersions counts what DeleteVersions would remove when given the same source, without deleting
+// anything. When w is not nil, one tab separated line of key, version id, kind, and size is written
+// to it per entry.
+func PlanVersions(source VersionSource, w io.Writer) (*Plan, error) {
+	plan := &Plan{Prefixes: map[string]*PlanCount{}}
+
+	for source.Next() {
+		if err := plan.add(w, source.Version()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := source.Err(); err != nil {
+		return nil, errors.Wrap(err, "package: s3svc => func: PlanVersions => method call s3svc.VersionSource.Next failed\n")
+	}
+
+	return plan, nil
+}
+
+func (p *Plan) add(w io.Writer, v *Version) error {
+	top := ""
+	if i := strings.Index(v.Key, "/"); i >= 0 {
+		top = v.Key[:i+1]
+	}
+
+	count, ok := p.Prefixes[top]
+	if !ok {
+		count = &PlanCount{}
+		p.Prefixes[top] = count
+	}
+
+	for _, pc := range []*PlanCount{&p.PlanCount, count} {
+		if v.IsDeleteMarker {
+			pc.DeleteMarkers++
+		} else {
+			pc.Versions++
+			pc.Bytes += v.Size
+		}
+	}
+
+	if w == nil {
+		return nil
+	}
+
+	kind := "version"
+	if v.IsDeleteMarker {
+		kind = "delete-marker"
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", v.Key, v.VersionID, kind, v.Size); err != nil {
+		return errors.Wrap(err, "package: s3svc => method: Plan.add => func call fmt.Fprintf failed\n")
+	}
+
+	return nil
+}
@@ -1,107 +1,521 @@
 package s3svc
 
 import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/pkg/errors"
 )
 
 // API represents a subset of the s3iface.
 type API interface {
+	AbortMultipartUpload(*s3.AbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error)
+	DeleteBucket(*s3.DeleteBucketInput) (*s3.DeleteBucketOutput, error)
+	DeleteBucketLifecycle(*s3.DeleteBucketLifecycleInput) (*s3.DeleteBucketLifecycleOutput, error)
 	DeleteObjects(*s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error)
+	GetBucketLifecycleConfiguration(*s3.GetBucketLifecycleConfigurationInput) (*s3.GetBucketLifecycleConfigurationOutput, error)
+	GetBucketLocation(*s3.GetBucketLocationInput) (*s3.GetBucketLocationOutput, error)
+	GetObject(*s3.GetObjectInput) (*s3.GetObjectOutput, error)
+	ListBuckets(*s3.ListBucketsInput) (*s3.ListBucketsOutput, error)
+	ListMultipartUploads(*s3.ListMultipartUploadsInput) (*s3.ListMultipartUploadsOutput, error)
 	ListObjectVersions(*s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error)
+	ListObjectsV2(*s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error)
+	ListParts(*s3.ListPartsInput) (*s3.ListPartsOutput, error)
+	PutBucketLifecycleConfiguration(*s3.PutBucketLifecycleConfigurationInput) (*s3.PutBucketLifecycleConfigurationOutput, error)
 }
 
 // Client provides a wrapper for s3api calls.
 type Client struct {
-	s3api   API
-	verbose bool
+	// counters are updated with sync/atomic, first in the struct to keep them 64-bit aligned
+	counters Counters
+
+	s3api       API
+	verbose     bool
+	concurrency int
+	batchSize   int
+
+	retryAttempts int
+	retryBackoff  time.Duration
+
+	progress func(Progress)
+	outcomes func([]Outcome) error
+
+	maxRPS   float64
+	throttle *throttle
+
+	lockAPI LockAPI
+	mfa     *mfa
 }
 
 // NewClient returns a client struct that provides an interface to the s3 api.
-func NewClient(s3 API, v bool) *Client {
-	return &Client{
-		s3api:   s3,
-		verbose: v,
+func NewClient(s3 API, v bool, options ...func(*Client)) *Client {
+	c := &Client{
+		s3api:       s3,
+		verbose:     v,
+		concurrency: 1,
+		batchSize:   maxDeleteBatch,
+
+		retryAttempts: 5,
+		retryBackoff:  200 * time.Millisecond,
+	}
+
+	for _, option := range options {
+		option(c)
 	}
+
+	c.throttle = newThrottle(c.concurrency, c.maxRPS)
+	c.s3api = &throttledAPI{api: s3, t: c.throttle, attempts: c.retryAttempts, backoff: c.retryBackoff}
+
+	return c
 }
 
-// DeleteBucketContents will remove all object versions and delete markers from the specified s3 bucket.
-func (c *Client) DeleteBucketContents(bucket string) ([]*s3.DeletedObject, error) {
+// WithMaxRPS caps how many requests per second the client sends to S3, zero means no cap.
+func WithMaxRPS(rps float64) func(*Client) {
+	return func(c *Client) {
+		if rps >= 0 {
+			c.maxRPS = rps
+		}
+	}
+}
 
-	// gonna fill this with items that were deleted by using append()
-	deletedObjects := []*s3.DeletedObject{}
+// ConcurrencyLimit returns how many requests the client currently lets run at once. It starts at
+// the concurrency, drops when S3 throttles requests and grows back once it stops.
+func (c *Client) ConcurrencyLimit() int {
+	return c.throttle.Limit()
+}
 
-	for {
-		// 2 API calls are needed to delete things in s3, the first will get a list
-		// of the objects in the bucket, and the second to delete those things
-		objIDs, objIDErr := c.GetObjectIdentifiers(bucket)
-		if objIDErr != nil {
-			return nil, errors.Wrap(objIDErr, "package: s3svc => method: DeleteBucketContents => method call s3svc.Client.GetObjectIdentifiers failed\n")
+// WithConcurrency sets how many DeleteObjects calls DeleteBucketContents, or AbortMultipartUpload and
+// ListParts calls, may have in flight at once.
+func WithConcurrency(n int) func(*Client) {
+	return func(c *Client) {
+		if n > 0 {
+			c.concurrency = n
 		}
+	}
+}
+
+// maxDeleteBatch is the most keys a single DeleteObjects request accepts.
+const maxDeleteBatch = 1000
 
-		// delete things until there's nothing left
-		if len(objIDs) == 0 {
-			return deletedObjects, nil
+// WithBatchSize sets how many keys DeleteBucketContents sends in each DeleteObjects request, up to 1000.
+func WithBatchSize(n int) func(*Client) {
+	return func(c *Client) {
+		if n > 0 && n <= maxDeleteBatch {
+			c.batchSize = n
 		}
+	}
+}
 
-		deleteIDs, deleteErr := c.DeleteObjects(bucket, objIDs)
-		if deleteErr != nil {
-			return nil, errors.Wrap(deleteErr, "package: s3svc => method: DeleteBucketContents => method call s3svc.Client.DeleteObjects failed\n")
+// WithRetries sets how many times DeleteObjects tries a key that fails with a retryable error code,
+// and any request S3 throttles, and the backoff before the first retry, which doubles on each
+// attempt after that.
+func WithRetries(attempts int, backoff time.Duration) func(*Client) {
+	return func(c *Client) {
+		if attempts > 0 {
+			c.retryAttempts = attempts
 		}
+		c.retryBackoff = backoff
+	}
+}
+
+// Progress is how far DeleteVersions has got, reported after every batch that completes the listing
+// up to it. Key and VersionID are the last entry of that batch: every entry the source yielded up to
+// and including it has been handled, so a later run can start listing right after it.
+type Progress struct {
+	Key       string
+	VersionID string
+	Deleted   int
+	Failed    int
+}
 
-		deletedObjects = append(deletedObjects, deleteIDs...)
+// WithProgress sets a function DeleteVersions calls with its progress so far, e.g. to save a checkpoint.
+// It's called from a single goroutine, one call at a time.
+func WithProgress(fn func(Progress)) func(*Client) {
+	return func(c *Client) {
+		c.progress = fn
 	}
 }
 
-// GetObjectIdentifiers returns object versions, delete markers, or both, from the specified s3 bucket.
-func (c *Client) GetObjectIdentifiers(bucket string) ([]*s3.ObjectIdentifier, error) {
-	resp, err := c.s3api.ListObjectVersions(&s3.ListObjectVersionsInput{Bucket: &bucket})
-	if err != nil {
-		return nil, errors.Wrap(err, "package: s3svc => method: GetObjectIdentifiers => method call s3api.ListObjectVersions failed\n")
+// Outcome is what became of one version DeleteVersions tried to delete, Failure is nil when it was deleted.
+type Outcome struct {
+	Version *Version
+	Failure *DeleteFailure
+}
+
+// WithOutcomes sets a function DeleteVersions calls with the outcome of every version in a batch as
+// soon as the batch finishes, e.g. to keep an audit log. Batches finish in any order, the function is
+// called from a single goroutine, one call at a time, and before any progress that covers the batch.
+// An error from it stops DeleteVersions like a failed batch.
+func WithOutcomes(fn func([]Outcome) error) func(*Client) {
+	return func(c *Client) {
+		c.outcomes = fn
 	}
+}
 
-	objectIdentifiers := make([]*s3.ObjectIdentifier, 0)
+// deleteBatch is one page of the version listing, numbered in the order it was listed.
+type deleteBatch struct {
+	seq      int
+	versions []*Version
+}
+
+// deleteResult is the outcome of listing or deleting the batch with the same seq.
+type deleteResult struct {
+	seq      int
+	outcomes []Outcome
+	last     *Version
+	err      error
+}
 
-	if resp.Versions != nil {
-		objVersions := make([]*s3.ObjectIdentifier, len(resp.Versions))
-		for i, obj := range resp.Versions {
-			objVersions[i] = &s3.ObjectIdentifier{
-				Key:       obj.Key,
-				VersionId: obj.VersionId,
+// DeleteTotals adds up what DeleteVersions did. DeleteMarkers counts the delete markers among the
+// deleted entries, Bytes the size of the deleted object versions.
+type DeleteTotals struct {
+	Deleted       int
+	DeleteMarkers int
+	Bytes         int64
+	Failed        int
+}
+
+func (t *DeleteTotals) add(outcomes []Outcome) {
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.Failure != nil:
+			t.Failed++
+		case outcome.Version.IsDeleteMarker:
+			t.Deleted++
+			t.DeleteMarkers++
+		default:
+			t.Deleted++
+			t.Bytes += outcome.Version.Size
+		}
+	}
+}
+
+// DeleteBucketContents will remove all object versions and delete markers from the specified s3 bucket.
+// When prefixes are given, only the keys that start with one of them are removed. Keys that could not
+// be deleted are counted in DeleteTotals.Failed and skipped.
+func (c *Client) DeleteBucketContents(bucket string, prefixes ...string) (*DeleteTotals, error) {
+	return c.DeleteVersions(bucket, c.NewVersionIterator(bucket, prefixes...))
+}
+
+// DeleteVersions removes every version and delete marker that source yields from the specified s3 bucket.
+// Keys that could not be deleted are counted in DeleteTotals.Failed and skipped. What became of each
+// version is only passed to the function set with WithOutcomes, batch by batch, rather than kept until
+// the end, so memory use does not grow with the size of the bucket.
+//
+// One goroutine reads the source and hands batches of up to 1000 keys to a pool of workers that
+// call DeleteObjects. If anything fails, reading stops and the error from the earliest batch in
+// listing order is returned, so the result does not depend on how the workers were scheduled.
+func (c *Client) DeleteVersions(bucket string, source VersionSource) (*DeleteTotals, error) {
+	batches := make(chan deleteBatch, c.concurrency)
+	results := make(chan deleteResult, c.concurrency)
+	stop := make(chan struct{})
+
+	// the lowest seq that has failed so far, workers skip any batch listed after it
+	var (
+		mu          sync.Mutex
+		firstFailed = -1
+	)
+
+	failedBefore := func(seq int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return firstFailed >= 0 && firstFailed < seq
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(batches)
+
+		seq := 0
+		send := func(versions []*Version) bool {
+			select {
+			case batches <- deleteBatch{seq: seq, versions: versions}:
+				seq++
+				return true
+			case <-stop:
+				return false
+			}
+		}
+
+		versions := make([]*Version, 0, c.batchSize)
+
+		for source.Next() {
+			atomic.AddInt64(&c.counters.Listed, 1)
+
+			versions = append(versions, source.Version())
+			if len(versions) < c.batchSize {
+				continue
+			}
+
+			if !send(versions) {
+				return
+			}
+			versions = make([]*Version, 0, c.batchSize)
+		}
+
+		if listErr := source.Err(); listErr != nil {
+			results <- deleteResult{
+				seq: seq,
+				err: errors.Wrap(listErr, "package: s3svc => method: DeleteVersions => method call s3svc.VersionSource.Next failed\n"),
 			}
+			return
+		}
+
+		if len(versions) > 0 {
+			send(versions)
 		}
+	}()
+
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for batch := range batches {
+				if failedBefore(batch.seq) {
+					continue
+				}
+
+				objIDs := make([]*s3.ObjectIdentifier, len(batch.versions))
+				for i, v := range batch.versions {
+					objIDs[i] = v.ObjectIdentifier()
+				}
+
+				result := deleteResult{seq: batch.seq, last: batch.versions[len(batch.versions)-1]}
+
+				deleted, deleteErr := c.DeleteObjects(bucket, objIDs)
+				if deleteErr != nil {
+					result.err = errors.Wrap(deleteErr, "package: s3svc => method: DeleteVersions => method call s3svc.Client.DeleteObjects failed\n")
+				} else {
+					result.outcomes = outcomes(batch.versions, deleted)
+				}
 
-		objectIdentifiers = append(objectIdentifiers, objVersions...)
+				results <- result
+			}
+		}()
 	}
 
-	if resp.DeleteMarkers != nil {
-		deadObjects := make([]*s3.ObjectIdentifier, len(resp.DeleteMarkers))
-		for i, obj := range resp.DeleteMarkers {
-			deadObjects[i] = &s3.ObjectIdentifier{
-				Key:       obj.Key,
-				VersionId: obj.VersionId,
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		firstErr error
+		stopOnce sync.Once
+		totals   = &DeleteTotals{}
+
+		// every batch with a seq below done has completed, progress adds up what they deleted, and
+		// pending holds the batches that completed ahead of an earlier one until it catches up
+		done     int
+		progress Progress
+		pending  = map[int]Progress{}
+	)
+
+	for result := range results {
+		if result.err == nil && c.outcomes != nil {
+			if err := c.outcomes(result.outcomes); err != nil {
+				result.err = errors.Wrap(err, "package: s3svc => method: DeleteVersions => func call outcomes failed\n")
+			}
+		}
+
+		if result.err == nil {
+			var batch DeleteTotals
+			batch.add(result.outcomes)
+			totals.add(result.outcomes)
+			c.count(batch)
+
+			pending[result.seq] = Progress{Key: result.last.Key, VersionID: result.last.VersionID, Deleted: batch.Deleted, Failed: batch.Failed}
+
+			before := done
+			for ; ; done++ {
+				next, ok := pending[done]
+				if !ok {
+					break
+				}
+				delete(pending, done)
+
+				progress.Key, progress.VersionID = next.Key, next.VersionID
+				progress.Deleted += next.Deleted
+				progress.Failed += next.Failed
+			}
+
+			if done > before && c.progress != nil {
+				c.progress(progress)
 			}
+			continue
+		}
+
+		mu.Lock()
+		if firstFailed < 0 || result.seq < firstFailed {
+			firstFailed = result.seq
+			firstErr = result.err
+		}
+		mu.Unlock()
+
+		stopOnce.Do(func() { close(stop) })
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return totals, nil
+}
+
+// outcomes pairs the versions of a batch with what DeleteObjects reported for them.
+func outcomes(versions []*Version, result *DeleteResult) []Outcome {
+	byID := make(map[string]*Version, len(versions))
+	for _, v := range versions {
+		byID[v.Key+"\x00"+v.VersionID] = v
+	}
+
+	version := func(key, versionID string) *Version {
+		if v, ok := byID[key+"\x00"+versionID]; ok {
+			return v
+		}
+		return &Version{Key: key, VersionID: versionID}
+	}
+
+	out := make([]Outcome, 0, len(result.Deleted)+len(result.Failed))
+	for _, deleted := range result.Deleted {
+		out = append(out, Outcome{Version: version(aws.StringValue(deleted.Key), aws.StringValue(deleted.VersionId))})
+	}
+	for _, failure := range result.Failed {
+		out = append(out, Outcome{Version: version(failure.Key, failure.VersionID), Failure: failure})
+	}
+
+	return out
+}
+
+// NormalizePrefixes sorts and de-duplicates a list of key prefixes, dropping any prefix that is
+// already covered by a shorter one. An empty list, or one containing "", selects the whole bucket.
+func NormalizePrefixes(prefixes []string) []string {
+	sorted := append([]string{}, prefixes...)
+	sort.Strings(sorted)
+
+	normalized := []string{}
+	for _, prefix := range sorted {
+		if prefix == "" {
+			return []string{""}
+		}
+
+		// sorting puts "a/" before "a/b/", so only the last kept prefix needs checking
+		if len(normalized) > 0 && strings.HasPrefix(prefix, normalized[len(normalized)-1]) {
+			continue
 		}
 
-		objectIdentifiers = append(objectIdentifiers, deadObjects...)
+		normalized = append(normalized, prefix)
+	}
+
+	if len(normalized) == 0 {
+		return []string{""}
 	}
 
-	return objectIdentifiers, nil
+	return normalized
+}
+
+// DeleteFailure describes a key that DeleteObjects could not remove.
+type DeleteFailure struct {
+	Key       string
+	VersionID string
+	Code      string
+	Message   string
+}
+
+// DeleteResult holds the per-key outcome of deleting objects.
+type DeleteResult struct {
+	Deleted []*s3.DeletedObject
+	Failed  []*DeleteFailure
+}
+
+// retryableCodes are the per-key error codes from DeleteObjects that may succeed if tried again.
+var retryableCodes = map[string]bool{
+	"InternalError":      true,
+	"OperationAborted":   true,
+	"RequestTimeout":     true,
+	"ServiceUnavailable": true,
+	"SlowDown":           true,
 }
 
 // DeleteObjects deletes the specified objects in an s3 bucket, including delete markers.
-func (c *Client) DeleteObjects(bucket string, objects []*s3.ObjectIdentifier) ([]*s3.DeletedObject, error) {
-	var deleteList s3.Delete
+// Keys that fail with a retryable error code are tried again with exponential backoff, anything
+// that still could not be deleted is reported in DeleteResult.Failed rather than as an error.
+func (c *Client) DeleteObjects(bucket string, objects []*s3.ObjectIdentifier) (*DeleteResult, error) {
+	result := &DeleteResult{
+		Deleted: []*s3.DeletedObject{},
+		Failed:  []*DeleteFailure{},
+	}
+
+	for attempt := 0; ; attempt++ {
+		var deleteList s3.Delete
+
+		deleteList.SetObjects(objects)
+
+		deleteResponse, deleteErr := c.deleteObjects(&s3.DeleteObjectsInput{
+			Bucket: &bucket,
+			Delete: &deleteList,
+		})
+		if deleteErr != nil {
+			return nil, errors.Wrap(deleteErr, "package: s3svc => method: DeleteObjects => method call s3api.DeleteObjects failed\n")
+		}
+
+		result.Deleted = append(result.Deleted, deleteResponse.Deleted...)
+
+		retry := []*s3.ObjectIdentifier{}
+		for _, keyErr := range deleteResponse.Errors {
+			code := aws.StringValue(keyErr.Code)
+			if retryableCodes[code] && attempt+1 < c.retryAttempts {
+				retry = append(retry, &s3.ObjectIdentifier{Key: keyErr.Key, VersionId: keyErr.VersionId})
+				continue
+			}
+
+			result.Failed = append(result.Failed, &DeleteFailure{
+				Key:       aws.StringValue(keyErr.Key),
+				VersionID: aws.StringValue(keyErr.VersionId),
+				Code:      code,
+				Message:   aws.StringValue(keyErr.Message),
+			})
+		}
+
+		if len(retry) == 0 {
+			return result, nil
+		}
 
-	deleteList.SetObjects(objects)
+		time.Sleep(c.retryBackoff << uint(attempt))
+		objects = retry
+	}
+}
 
-	deleteResponse, deleteErr := c.s3api.DeleteObjects(&s3.DeleteObjectsInput{
-		Bucket: &bucket,
-		Delete: &deleteList,
-	})
-	if deleteErr != nil {
-		return nil, errors.Wrap(deleteErr, "package: s3svc => method: DeleteObjects => method call s3api.DeleteObjects failed\n")
+// deleteObjects sends a DeleteObjects request, with an MFA token when the client has one. A request
+// S3 rejects for its token is sent once more with a fresh one.
+func (c *Client) deleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	if c.mfa == nil {
+		return c.s3api.DeleteObjects(input)
 	}
 
-	return deleteResponse.Deleted, nil
+	for retried := false; ; retried = true {
+		value, err := c.mfa.current()
+		if err != nil {
+			return nil, err
+		}
+
+		input.MFA = aws.String(value)
+
+		resp, err := c.s3api.DeleteObjects(input)
+		if !isMFAError(err) || retried {
+			return resp, err
+		}
+
+		c.mfa.reject(value)
+	}
 }
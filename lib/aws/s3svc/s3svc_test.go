@@ -1,7 +1,10 @@
 package s3svc_test
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -34,168 +37,9 @@ var _ = Describe("S3svc", func() {
 		s3Client = s3svc.NewClient(fakeS3, false)
 	})
 
-	Describe("GetObjectIdentifiers", func() {
-		var (
-			actualResp   []*s3.ObjectIdentifier
-			expectedResp []*s3.ObjectIdentifier
-		)
-
-		JustBeforeEach(func() {
-			fakeS3.ListObjectVersionsReturns(listObjVersionOutput, listObjVersionOutputError)
-			actualResp, actualErr = s3Client.GetObjectIdentifiers(bucket)
-		})
-
-		Context("when everything is fine", func() {
-			BeforeEach(func() {
-				listObjVersionOutput = &s3.ListObjectVersionsOutput{
-					IsTruncated:     aws.Bool(false),
-					KeyMarker:       aws.String(""),
-					MaxKeys:         aws.Int64(1000),
-					Name:            aws.String(bucket),
-					Prefix:          aws.String(""),
-					VersionIdMarker: aws.String(""),
-				}
-				listObjVersionOutputError = nil
-			})
-
-			It("should not produce an error", func() {
-				Expect(actualErr).To(BeNil())
-			})
-
-			Context("when there are no objects in the bucket", func() {
-				BeforeEach(func() {
-					expectedResp = []*s3.ObjectIdentifier{}
-				})
-
-				It("should return an empty slice", func() {
-					Expect(actualResp).NotTo(BeNil())
-					Expect(actualResp).To(Equal(expectedResp))
-				})
-			})
-
-			Context("when there are only object versions", func() {
-				BeforeEach(func() {
-					listObjVersionOutput.Versions = []*s3.ObjectVersion{
-						&s3.ObjectVersion{
-							ETag:         aws.String("\"4dc5bf55892540efa31afa0463667a82\""),
-							Key:          aws.String("index"),
-							LastModified: aws.Time(time.Now()),
-							Owner: &s3.Owner{
-								DisplayName: aws.String("GetTerminus"),
-								ID:          aws.String("7c799faaab6148a7b7104bc9a3835f65"),
-							},
-							Size:         aws.Int64(1024),
-							StorageClass: aws.String("STANDARD"),
-							VersionId:    aws.String("null"),
-						},
-					}
-
-					expectedResp = []*s3.ObjectIdentifier{
-						&s3.ObjectIdentifier{
-							Key:       aws.String("index"),
-							VersionId: aws.String("null"),
-						},
-					}
-				})
-
-				It("should produce a slice of *s3.ObjectVersion", func() {
-					Expect(actualResp).NotTo(BeNil())
-					Expect(actualResp).To(Equal(expectedResp))
-				})
-			})
-
-			Context("when there are only delete markers", func() {
-				BeforeEach(func() {
-					listObjVersionOutput.DeleteMarkers = []*s3.DeleteMarkerEntry{
-						&s3.DeleteMarkerEntry{
-							IsLatest:  aws.Bool(true),
-							Key:       aws.String("index"),
-							VersionId: aws.String("5a8a51ef218747c0aafddc3009c22cf7"),
-						},
-					}
-
-					expectedResp = []*s3.ObjectIdentifier{
-						&s3.ObjectIdentifier{
-							Key:       aws.String("index"),
-							VersionId: aws.String("5a8a51ef218747c0aafddc3009c22cf7"),
-						},
-					}
-				})
-
-				It("should produce a slice of *s3.ObjectVersion", func() {
-					Expect(actualResp).NotTo(BeNil())
-					Expect(actualResp).To(Equal(expectedResp))
-				})
-			})
-
-			Context("when there are both object versions and delete markers", func() {
-				BeforeEach(func() {
-					listObjVersionOutput.Versions = []*s3.ObjectVersion{
-						&s3.ObjectVersion{
-							ETag:         aws.String("\"4dc5bf55892540efa31afa0463667a82\""),
-							Key:          aws.String("obj1"),
-							LastModified: aws.Time(time.Now()),
-							Owner: &s3.Owner{
-								DisplayName: aws.String("GetTerminus"),
-								ID:          aws.String("7c799faaab6148a7b7104bc9a3835f65"),
-							},
-							Size:         aws.Int64(1024),
-							StorageClass: aws.String("STANDARD"),
-							VersionId:    aws.String("c3ecadb1b13d4278a67a9a07927222b2"),
-						},
-					}
-
-					listObjVersionOutput.DeleteMarkers = []*s3.DeleteMarkerEntry{
-						&s3.DeleteMarkerEntry{
-							IsLatest:     aws.Bool(true),
-							Key:          aws.String("dm1"),
-							LastModified: aws.Time(time.Now()),
-							Owner: &s3.Owner{
-								DisplayName: aws.String("GetTerminus"),
-								ID:          aws.String("7c799faaab6148a7b7104bc9a3835f65"),
-							},
-							VersionId: aws.String("5a8a51ef218747c0aafddc3009c22cf7"),
-						},
-					}
-
-					expectedResp = []*s3.ObjectIdentifier{
-						&s3.ObjectIdentifier{
-							Key:       aws.String("dm1"),
-							VersionId: aws.String("5a8a51ef218747c0aafddc3009c22cf7"),
-						},
-						&s3.ObjectIdentifier{
-							Key:       aws.String("obj1"),
-							VersionId: aws.String("c3ecadb1b13d4278a67a9a07927222b2"),
-						},
-					}
-				})
-
-				It("should produce a slice of *s3.ObjectVersion", func() {
-					Expect(actualResp).NotTo(BeNil())
-					Expect(actualResp).To(ConsistOf(expectedResp))
-				})
-			})
-		})
-
-		Context("when s3.ListObjectVersions fails", func() {
-			BeforeEach(func() {
-				listObjVersionOutputError = errors.New("fail")
-				expectedResp = nil
-			})
-
-			It("should produce an error", func() {
-				Expect(actualErr).NotTo(BeNil())
-			})
-
-			It("should not return any s3.ObjectIdentifiers", func() {
-				Expect(actualResp).To(BeNil())
-			})
-		})
-	})
-
 	Describe("DeleteObjects", func() {
 		var (
-			actualResp   []*s3.DeletedObject
+			actualResp   *s3svc.DeleteResult
 			expectedResp []*s3.DeletedObject
 
 			deleteObjectIDs []*s3.ObjectIdentifier
@@ -241,7 +85,7 @@ var _ = Describe("S3svc", func() {
 
 			It("should produce a slice of *s3.DeletedObject", func() {
 				Expect(actualErr).To(BeNil())
-				Expect(actualResp).To(ConsistOf(expectedResp))
+				Expect(actualResp.Deleted).To(ConsistOf(expectedResp))
 			})
 		})
 
@@ -259,10 +103,233 @@ var _ = Describe("S3svc", func() {
 		})
 	})
 
+	Describe("DeleteObjects with per-key errors", func() {
+		var (
+			actualResp *s3svc.DeleteResult
+			objectIDs  []*s3.ObjectIdentifier
+		)
+
+		BeforeEach(func() {
+			s3Client = s3svc.NewClient(fakeS3, false, s3svc.WithRetries(3, time.Millisecond))
+			objectIDs = []*s3.ObjectIdentifier{
+				&s3.ObjectIdentifier{Key: aws.String("ok"), VersionId: aws.String("v1")},
+				&s3.ObjectIdentifier{Key: aws.String("flaky"), VersionId: aws.String("v2")},
+				&s3.ObjectIdentifier{Key: aws.String("locked"), VersionId: aws.String("v3")},
+			}
+
+			fakeS3.DeleteObjectsReturnsOnCall(0, &s3.DeleteObjectsOutput{
+				Deleted: []*s3.DeletedObject{
+					&s3.DeletedObject{Key: aws.String("ok"), VersionId: aws.String("v1")},
+				},
+				Errors: []*s3.Error{
+					&s3.Error{Code: aws.String("SlowDown"), Key: aws.String("flaky"), Message: aws.String("Please reduce your request rate."), VersionId: aws.String("v2")},
+					&s3.Error{Code: aws.String("AccessDenied"), Key: aws.String("locked"), Message: aws.String("Access Denied"), VersionId: aws.String("v3")},
+				},
+			}, nil)
+		})
+
+		JustBeforeEach(func() {
+			actualResp, actualErr = s3Client.DeleteObjects(bucket, objectIDs)
+		})
+
+		Context("when a retryable error clears up", func() {
+			BeforeEach(func() {
+				fakeS3.DeleteObjectsReturnsOnCall(1, &s3.DeleteObjectsOutput{
+					Deleted: []*s3.DeletedObject{
+						&s3.DeletedObject{Key: aws.String("flaky"), VersionId: aws.String("v2")},
+					},
+				}, nil)
+			})
+
+			It("should not produce an error", func() {
+				Expect(actualErr).To(BeNil())
+			})
+
+			It("should only retry the keys with retryable errors", func() {
+				Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(2))
+				Expect(fakeS3.DeleteObjectsArgsForCall(1).Delete.Objects).To(Equal([]*s3.ObjectIdentifier{
+					&s3.ObjectIdentifier{Key: aws.String("flaky"), VersionId: aws.String("v2")},
+				}))
+			})
+
+			It("should report the deleted keys", func() {
+				Expect(actualResp.Deleted).To(HaveLen(2))
+			})
+
+			It("should report the keys with permanent errors as failed", func() {
+				Expect(actualResp.Failed).To(Equal([]*s3svc.DeleteFailure{
+					&s3svc.DeleteFailure{Key: "locked", VersionID: "v3", Code: "AccessDenied", Message: "Access Denied"},
+				}))
+			})
+		})
+
+		Context("when a retryable error persists", func() {
+			BeforeEach(func() {
+				stillSlow := &s3.DeleteObjectsOutput{
+					Errors: []*s3.Error{
+						&s3.Error{Code: aws.String("SlowDown"), Key: aws.String("flaky"), Message: aws.String("Please reduce your request rate."), VersionId: aws.String("v2")},
+					},
+				}
+				fakeS3.DeleteObjectsReturns(stillSlow, nil)
+			})
+
+			It("should give up after the configured number of attempts", func() {
+				Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(3))
+			})
+
+			It("should report the key as failed with its last error code", func() {
+				Expect(actualErr).To(BeNil())
+				Expect(actualResp.Failed).To(ConsistOf(
+					&s3svc.DeleteFailure{Key: "locked", VersionID: "v3", Code: "AccessDenied", Message: "Access Denied"},
+					&s3svc.DeleteFailure{Key: "flaky", VersionID: "v2", Code: "SlowDown", Message: "Please reduce your request rate."},
+				))
+			})
+		})
+	})
+
+	Describe("DeleteBucketContents with undeletable keys", func() {
+		var (
+			fake       *fakeBucket
+			actualResp *s3svc.DeleteTotals
+		)
+
+		BeforeEach(func() {
+			fake = &fakeBucket{pageSize: 2}
+			fake.addVersion("a", "v1")
+			fake.addVersion("held", "v2")
+			fake.addVersion("held", "v3")
+			fake.addVersion("z", "v4")
+			fake.failures = map[string]string{"held": "AccessDenied"}
+
+			fakeS3.ListObjectVersionsStub = fake.ListObjectVersions
+			fakeS3.DeleteObjectsStub = fake.DeleteObjects
+		})
+
+		JustBeforeEach(func() {
+			actualResp, actualErr = s3Client.DeleteBucketContents(bucket)
+		})
+
+		It("should finish without re-listing the undeletable keys", func() {
+			Expect(actualErr).To(BeNil())
+			Expect(fakeS3.ListObjectVersionsCallCount()).To(Equal(2))
+		})
+
+		It("should delete everything else", func() {
+			Expect(fake.remaining()).To(ConsistOf("held@v2", "held@v3"))
+			Expect(actualResp.Deleted).To(Equal(2))
+		})
+
+		It("should count the failures", func() {
+			Expect(actualResp.Failed).To(Equal(2))
+			Expect(s3Client.Counters().Failed).To(Equal(int64(2)))
+		})
+	})
+
+	Describe("DeleteBucketContents with outcomes", func() {
+		var (
+			fake     *fakeBucket
+			outcomes []s3svc.Outcome
+			progress []s3svc.Progress
+			failWith error
+		)
+
+		BeforeEach(func() {
+			fake = &fakeBucket{pageSize: 2}
+			fake.addVersion("a", "v1")
+			fake.addDeleteMarker("held", "v2")
+			fake.addVersion("held", "v3")
+			fake.addVersion("z", "v4")
+			fake.failures = map[string]string{"held": "AccessDenied"}
+
+			outcomes, progress, failWith = nil, nil, nil
+			fakeS3.ListObjectVersionsStub = fake.ListObjectVersions
+			fakeS3.DeleteObjectsStub = fake.DeleteObjects
+		})
+
+		JustBeforeEach(func() {
+			s3Client = s3svc.NewClient(fakeS3, false,
+				s3svc.WithBatchSize(2),
+				s3svc.WithOutcomes(func(batch []s3svc.Outcome) error {
+					if len(progress) > 0 && progress[len(progress)-1].Key >= batch[0].Version.Key {
+						return errors.New("progress reported ahead of the outcomes")
+					}
+					outcomes = append(outcomes, batch...)
+					return failWith
+				}),
+				s3svc.WithProgress(func(p s3svc.Progress) { progress = append(progress, p) }),
+			)
+			_, actualErr = s3Client.DeleteBucketContents(bucket)
+		})
+
+		It("should report every version with what became of it", func() {
+			Expect(actualErr).To(BeNil())
+			Expect(outcomes).To(HaveLen(4))
+
+			Expect(outcomes[0].Version.Key).To(Equal("a"))
+			Expect(outcomes[0].Version.Size).To(Equal(int64(1024)))
+			Expect(outcomes[0].Version.LastModified).NotTo(BeZero())
+			Expect(outcomes[0].Failure).To(BeNil())
+
+			Expect(outcomes[1].Version.VersionID).To(Equal("v2"))
+			Expect(outcomes[1].Version.IsDeleteMarker).To(BeTrue())
+			Expect(outcomes[1].Failure.Code).To(Equal("AccessDenied"))
+
+			Expect(outcomes[2].Version.Key).To(Equal("z"))
+			Expect(outcomes[2].Failure).To(BeNil())
+			Expect(outcomes[3].Version.VersionID).To(Equal("v3"))
+			Expect(outcomes[3].Failure).NotTo(BeNil())
+		})
+
+		It("should report the outcomes of a batch before progress that covers it", func() {
+			Expect(actualErr).To(BeNil())
+			Expect(progress).To(HaveLen(2))
+		})
+
+		Context("when the outcome function fails", func() {
+			BeforeEach(func() {
+				failWith = errors.New("disk full")
+			})
+
+			It("should stop and return its error", func() {
+				Expect(actualErr).To(MatchError(ContainSubstring("disk full")))
+				Expect(progress).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("DeleteVersions with keys from a list", func() {
+		It("should leave out the version ID for keys given without one", func() {
+			fakeS3.DeleteObjectsReturns(&s3.DeleteObjectsOutput{
+				Deleted: []*s3.DeletedObject{
+					{Key: aws.String("a"), DeleteMarker: aws.Bool(true), DeleteMarkerVersionId: aws.String("m1")},
+					{Key: aws.String("b"), VersionId: aws.String("v2")},
+				},
+			}, nil)
+
+			var outcomes []s3svc.Outcome
+			s3Client = s3svc.NewClient(fakeS3, false, s3svc.WithOutcomes(func(batch []s3svc.Outcome) error {
+				outcomes = append(outcomes, batch...)
+				return nil
+			}))
+
+			versions := []*s3svc.Version{{Key: "a"}, {Key: "b", VersionID: "v2"}}
+			totals, err := s3Client.DeleteVersions(bucket, &sliceSource{versions: versions})
+			Expect(err).To(BeNil())
+			Expect(totals.Deleted).To(Equal(2))
+
+			objects := fakeS3.DeleteObjectsArgsForCall(0).Delete.Objects
+			Expect(objects[0].VersionId).To(BeNil())
+			Expect(aws.StringValue(objects[1].VersionId)).To(Equal("v2"))
+
+			Expect(outcomes).To(HaveLen(2))
+			Expect(outcomes[0].Version).To(BeIdenticalTo(versions[0]))
+		})
+	})
+
 	Describe("DeleteBucketContents", func() {
 		var (
-			actualResp   []*s3.DeletedObject
-			expectedResp []*s3.DeletedObject
+			actualResp   *s3svc.DeleteTotals
+			expectedResp s3svc.DeleteTotals
 		)
 
 		JustBeforeEach(func() {
@@ -282,15 +349,15 @@ var _ = Describe("S3svc", func() {
 
 			Context("when the bucket is already empty", func() {
 				BeforeEach(func() {
-					expectedResp = []*s3.DeletedObject{}
+					expectedResp = s3svc.DeleteTotals{}
 				})
 
 				It("should not produce an error", func() {
 					Expect(actualErr).To(BeNil())
 				})
 
-				It("should return an emtpy list of s3.DeletedObjects", func() {
-					Expect(actualResp).To(Equal(expectedResp))
+				It("should return zero totals", func() {
+					Expect(*actualResp).To(Equal(expectedResp))
 				})
 
 				It("should not call the DeleteObjects method", func() {
@@ -301,8 +368,13 @@ var _ = Describe("S3svc", func() {
 			Context("when the bucket is not emtpy", func() {
 				Context("and the results from s3.ListObjectVersions are paginated", func() {
 					BeforeEach(func() {
+						s3Client = s3svc.NewClient(fakeS3, false, s3svc.WithBatchSize(1))
+
 						fakeS3.ListObjectVersionsReturnsOnCall(0,
 							&s3.ListObjectVersionsOutput{
+								IsTruncated:         aws.Bool(true),
+								NextKeyMarker:       aws.String("obj1"),
+								NextVersionIdMarker: aws.String("c3ecadb1b13d4278a67a9a07927222b2"),
 								Versions: []*s3.ObjectVersion{
 									&s3.ObjectVersion{
 										ETag:         aws.String("\"4dc5bf55892540efa31afa0463667a82\""),
@@ -322,6 +394,9 @@ var _ = Describe("S3svc", func() {
 
 						fakeS3.ListObjectVersionsReturnsOnCall(1,
 							&s3.ListObjectVersionsOutput{
+								IsTruncated:         aws.Bool(true),
+								NextKeyMarker:       aws.String("obj1"),
+								NextVersionIdMarker: aws.String("5a8a51ef218747c0aafddc3009c22cf7"),
 								DeleteMarkers: []*s3.DeleteMarkerEntry{
 									&s3.DeleteMarkerEntry{
 										IsLatest:     aws.Bool(true),
@@ -363,18 +438,7 @@ var _ = Describe("S3svc", func() {
 							},
 							deleteObjectsOutputError)
 
-						expectedResp = []*s3.DeletedObject{
-							&s3.DeletedObject{
-								Key:       aws.String("obj1"),
-								VersionId: aws.String("c3ecadb1b13d4278a67a9a07927222b2"),
-							},
-							&s3.DeletedObject{
-								DeleteMarker:          aws.Bool(true),
-								DeleteMarkerVersionId: aws.String("1a624c9c02b74b09845f0623521ea571"),
-								Key:       aws.String("obj1"),
-								VersionId: aws.String("5a8a51ef218747c0aafddc3009c22cf7"),
-							},
-						}
+						expectedResp = s3svc.DeleteTotals{Deleted: 2, DeleteMarkers: 1, Bytes: 1024}
 					})
 
 					It("should call ListObjectVersions 3 times", func() {
@@ -385,8 +449,14 @@ var _ = Describe("S3svc", func() {
 						Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(2))
 					})
 
-					It("should delete the contents of the bucket", func() {
-						Expect(actualResp).To(ConsistOf(expectedResp))
+					It("should add up what was deleted", func() {
+						Expect(*actualResp).To(Equal(expectedResp))
+					})
+
+					It("should continue listing from the markers of the previous page", func() {
+						Expect(fakeS3.ListObjectVersionsArgsForCall(0).KeyMarker).To(BeNil())
+						Expect(fakeS3.ListObjectVersionsArgsForCall(1).KeyMarker).To(Equal(aws.String("obj1")))
+						Expect(fakeS3.ListObjectVersionsArgsForCall(2).VersionIdMarker).To(Equal(aws.String("5a8a51ef218747c0aafddc3009c22cf7")))
 					})
 				})
 			})
@@ -447,4 +517,493 @@ var _ = Describe("S3svc", func() {
 			})
 		})
 	})
+
+	Describe("DeleteBucketContents with prefixes", func() {
+		var (
+			fake     *fakeBucket
+			prefixes []string
+		)
+
+		BeforeEach(func() {
+			fake = &fakeBucket{}
+			fake.addVersion("tenant-a/data.csv", "v1")
+			fake.addVersion("tenant-a/nested/data.csv", "v2")
+			fake.addDeleteMarker("tenant-a/gone.csv", "dm1")
+			fake.addVersion("tenant-ab/data.csv", "v3")
+			fake.addVersion("tenant-b/data.csv", "v4")
+			fake.addDeleteMarker("tenant-b/gone.csv", "dm2")
+			fake.addVersion("root.csv", "v5")
+
+			fakeS3.ListObjectVersionsStub = fake.ListObjectVersions
+			fakeS3.DeleteObjectsStub = fake.DeleteObjects
+		})
+
+		JustBeforeEach(func() {
+			_, actualErr = s3Client.DeleteBucketContents(bucket, prefixes...)
+		})
+
+		Context("when a single prefix is given", func() {
+			BeforeEach(func() {
+				prefixes = []string{"tenant-a/"}
+			})
+
+			It("should not produce an error", func() {
+				Expect(actualErr).To(BeNil())
+			})
+
+			It("should only delete versions and delete markers under the prefix", func() {
+				Expect(fake.remaining()).To(ConsistOf(
+					"tenant-ab/data.csv@v3",
+					"tenant-b/data.csv@v4",
+					"tenant-b/gone.csv@dm2",
+					"root.csv@v5",
+				))
+			})
+
+			It("should only send keys under the prefix to s3.DeleteObjects", func() {
+				for i := 0; i < fakeS3.DeleteObjectsCallCount(); i++ {
+					for _, obj := range fakeS3.DeleteObjectsArgsForCall(i).Delete.Objects {
+						Expect(*obj.Key).To(HavePrefix("tenant-a/"))
+					}
+				}
+			})
+		})
+
+		Context("when several prefixes are given", func() {
+			BeforeEach(func() {
+				prefixes = []string{"tenant-b/", "tenant-a/nested/"}
+			})
+
+			It("should delete everything under each prefix and nothing else", func() {
+				Expect(actualErr).To(BeNil())
+				Expect(fake.remaining()).To(ConsistOf(
+					"tenant-a/data.csv@v1",
+					"tenant-a/gone.csv@dm1",
+					"tenant-ab/data.csv@v3",
+					"root.csv@v5",
+				))
+			})
+		})
+
+		Context("when no prefixes are given", func() {
+			BeforeEach(func() {
+				prefixes = nil
+			})
+
+			It("should empty the whole bucket", func() {
+				Expect(actualErr).To(BeNil())
+				Expect(fake.remaining()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("NormalizePrefixes", func() {
+		It("should select the whole bucket when there are no prefixes", func() {
+			Expect(s3svc.NormalizePrefixes(nil)).To(Equal([]string{""}))
+		})
+
+		It("should select the whole bucket when one of the prefixes is empty", func() {
+			Expect(s3svc.NormalizePrefixes([]string{"a/", ""})).To(Equal([]string{""}))
+		})
+
+		It("should drop duplicates and prefixes covered by a shorter one", func() {
+			Expect(s3svc.NormalizePrefixes([]string{"b/", "a/b/", "a/", "b/", "ab/"})).To(Equal([]string{"a/", "ab/", "b/"}))
+		})
+	})
+
+	Describe("PlanVersions with a version listing", func() {
+		var (
+			fake     *fakeBucket
+			prefixes []string
+			listing  *bytes.Buffer
+			plan     *s3svc.Plan
+		)
+
+		BeforeEach(func() {
+			fake = &fakeBucket{pageSize: 2}
+			fake.addVersion("logs/a.log", "v1")
+			fake.addVersion("logs/a.log", "v2")
+			fake.addDeleteMarker("logs/b.log", "dm1")
+			fake.addVersion("data/part-0", "v3")
+			fake.addVersion("index.html", "v4")
+
+			fakeS3.ListObjectVersionsStub = fake.ListObjectVersions
+			fakeS3.DeleteObjectsStub = fake.DeleteObjects
+
+			prefixes = nil
+			listing = &bytes.Buffer{}
+		})
+
+		JustBeforeEach(func() {
+			plan, actualErr = s3svc.PlanVersions(s3Client.NewVersionIterator(bucket, prefixes...), listing)
+		})
+
+		It("should not produce an error", func() {
+			Expect(actualErr).To(BeNil())
+		})
+
+		It("should follow the version markers through every page", func() {
+			Expect(fakeS3.ListObjectVersionsCallCount()).To(Equal(3))
+			Expect(fakeS3.ListObjectVersionsArgsForCall(1).KeyMarker).To(Equal(aws.String("index.html")))
+			Expect(fakeS3.ListObjectVersionsArgsForCall(1).VersionIdMarker).To(Equal(aws.String("v4")))
+		})
+
+		It("should never call s3.DeleteObjects", func() {
+			Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(0))
+			Expect(fake.remaining()).To(HaveLen(5))
+		})
+
+		It("should count versions, delete markers, and bytes", func() {
+			Expect(plan.Versions).To(Equal(int64(4)))
+			Expect(plan.DeleteMarkers).To(Equal(int64(1)))
+			Expect(plan.Bytes).To(Equal(int64(4096)))
+		})
+
+		It("should count per top-level prefix", func() {
+			Expect(plan.Prefixes).To(HaveLen(3))
+			Expect(*plan.Prefixes["logs/"]).To(Equal(s3svc.PlanCount{Versions: 2, DeleteMarkers: 1, Bytes: 2048}))
+			Expect(*plan.Prefixes["data/"]).To(Equal(s3svc.PlanCount{Versions: 1, Bytes: 1024}))
+			Expect(*plan.Prefixes[""]).To(Equal(s3svc.PlanCount{Versions: 1, Bytes: 1024}))
+		})
+
+		It("should write every key and version to the listing", func() {
+			Expect(strings.Split(strings.TrimSpace(listing.String()), "\n")).To(ConsistOf(
+				"data/part-0\tv3\tversion\t1024",
+				"index.html\tv4\tversion\t1024",
+				"logs/a.log\tv1\tversion\t1024",
+				"logs/a.log\tv2\tversion\t1024",
+				"logs/b.log\tdm1\tdelete-marker\t0",
+			))
+		})
+
+		Context("when prefixes are given", func() {
+			BeforeEach(func() {
+				prefixes = []string{"logs/"}
+			})
+
+			It("should only count keys under the prefixes", func() {
+				Expect(plan.Versions).To(Equal(int64(2)))
+				Expect(plan.DeleteMarkers).To(Equal(int64(1)))
+				Expect(plan.Prefixes).To(HaveKey("logs/"))
+				Expect(plan.Prefixes).To(HaveLen(1))
+			})
+		})
+
+		Context("when s3.ListObjectVersions fails", func() {
+			BeforeEach(func() {
+				fakeS3.ListObjectVersionsStub = nil
+				fakeS3.ListObjectVersionsReturns(nil, errors.New("fail"))
+			})
+
+			It("should return an error", func() {
+				Expect(plan).To(BeNil())
+				Expect(actualErr).NotTo(BeNil())
+			})
+		})
+	})
+
+	Describe("DeleteBucketContents with concurrent workers", func() {
+		var (
+			fake       *fakeBucket
+			actualResp *s3svc.DeleteTotals
+			progress   []s3svc.Progress
+		)
+
+		BeforeEach(func() {
+			fake = &fakeBucket{pageSize: 10}
+			for i := 0; i < 200; i++ {
+				fake.addVersion(fmt.Sprintf("key-%03d", i), fmt.Sprintf("v%03d", i))
+			}
+
+			progress = nil
+			fakeS3.ListObjectVersionsStub = fake.ListObjectVersions
+			fakeS3.DeleteObjectsStub = fake.DeleteObjects
+			s3Client = s3svc.NewClient(fakeS3, false,
+				s3svc.WithConcurrency(4),
+				s3svc.WithBatchSize(10),
+				s3svc.WithProgress(func(p s3svc.Progress) { progress = append(progress, p) }),
+			)
+		})
+
+		JustBeforeEach(func() {
+			actualResp, actualErr = s3Client.DeleteBucketContents(bucket)
+		})
+
+		Context("when everything works", func() {
+			It("should empty the bucket", func() {
+				Expect(actualErr).To(BeNil())
+				Expect(fake.remaining()).To(BeEmpty())
+			})
+
+			It("should list each page exactly once", func() {
+				Expect(fakeS3.ListObjectVersionsCallCount()).To(Equal(20))
+				Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(20))
+			})
+
+			It("should add up what was deleted", func() {
+				Expect(*actualResp).To(Equal(s3svc.DeleteTotals{Deleted: 200, Bytes: 200 * 1024}))
+			})
+
+			It("should keep the client's counters up to date", func() {
+				Expect(s3Client.Counters()).To(Equal(s3svc.Counters{Listed: 200, Deleted: 200, Bytes: 200 * 1024}))
+			})
+
+			It("should report progress in listing order up to the last version", func() {
+				Expect(progress).NotTo(BeEmpty())
+				for i := 1; i < len(progress); i++ {
+					Expect(progress[i].Key > progress[i-1].Key).To(BeTrue())
+					Expect(progress[i].Deleted).To(BeNumerically(">", progress[i-1].Deleted))
+				}
+				Expect(progress[len(progress)-1]).To(Equal(s3svc.Progress{Key: "key-199", VersionID: "v199", Deleted: 200}))
+			})
+		})
+
+		Context("when several batches fail", func() {
+			BeforeEach(func() {
+				fakeS3.DeleteObjectsStub = func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+					first := *input.Delete.Objects[0].Key
+					switch first {
+					case "key-020":
+						// finish after the later batch has already failed
+						time.Sleep(50 * time.Millisecond)
+						return nil, errors.New("batch starting at " + first)
+					case "key-030":
+						return nil, errors.New("batch starting at " + first)
+					}
+
+					return fake.DeleteObjects(input)
+				}
+			})
+
+			It("should return the error from the earliest batch", func() {
+				Expect(actualResp).To(BeNil())
+				Expect(actualErr).To(MatchError(ContainSubstring("batch starting at key-020")))
+			})
+
+			It("should stop listing", func() {
+				Expect(fakeS3.ListObjectVersionsCallCount()).To(BeNumerically("<", 20))
+			})
+
+			It("should still finish the batches listed before the failure", func() {
+				Expect(fake.remaining()).NotTo(ContainElement("key-000@v000"))
+				Expect(fake.remaining()).NotTo(ContainElement("key-019@v019"))
+			})
+
+			It("should not report progress past the failed batch", func() {
+				Expect(progress).NotTo(BeEmpty())
+				Expect(progress[len(progress)-1]).To(Equal(s3svc.Progress{Key: "key-019", VersionID: "v019", Deleted: 20}))
+			})
+		})
+
+		Context("when listing fails part way through", func() {
+			BeforeEach(func() {
+				fakeS3.ListObjectVersionsStub = func(input *s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error) {
+					if aws.StringValue(input.KeyMarker) == "key-049" {
+						return nil, errors.New("list fail")
+					}
+
+					return fake.ListObjectVersions(input)
+				}
+			})
+
+			It("should return the listing error", func() {
+				Expect(actualResp).To(BeNil())
+				Expect(actualErr).To(MatchError(ContainSubstring("list fail")))
+			})
+
+			It("should delete what was listed before the error", func() {
+				Expect(fake.remaining()).To(HaveLen(150))
+			})
+		})
+	})
+
+	Describe("VersionIterator", func() {
+		var (
+			prefixes   []string
+			startAfter *s3svc.Version
+			versions   []*s3svc.Version
+		)
+
+		BeforeEach(func() {
+			prefixes = nil
+			startAfter = nil
+		})
+
+		JustBeforeEach(func() {
+			versions = nil
+			it := s3Client.NewVersionIterator(bucket, prefixes...)
+			if startAfter != nil {
+				it.StartAfter(startAfter.Key, startAfter.VersionID)
+			}
+			for it.Next() {
+				versions = append(versions, it.Version())
+			}
+			actualErr = it.Err()
+		})
+
+		Context("when a page has versions and delete markers for the same key", func() {
+			BeforeEach(func() {
+				now := time.Now()
+				fakeS3.ListObjectVersionsReturns(&s3.ListObjectVersionsOutput{
+					Versions: []*s3.ObjectVersion{
+						&s3.ObjectVersion{Key: aws.String("a"), VersionId: aws.String("a1"), IsLatest: aws.Bool(true), LastModified: aws.Time(now), Size: aws.Int64(10)},
+						&s3.ObjectVersion{Key: aws.String("b"), VersionId: aws.String("b2"), LastModified: aws.Time(now.Add(-2 * time.Hour))},
+						&s3.ObjectVersion{Key: aws.String("b"), VersionId: aws.String("b1"), LastModified: aws.Time(now.Add(-4 * time.Hour))},
+					},
+					DeleteMarkers: []*s3.DeleteMarkerEntry{
+						&s3.DeleteMarkerEntry{Key: aws.String("b"), VersionId: aws.String("dm2"), IsLatest: aws.Bool(true), LastModified: aws.Time(now.Add(-1 * time.Hour))},
+						&s3.DeleteMarkerEntry{Key: aws.String("b"), VersionId: aws.String("dm1"), LastModified: aws.Time(now.Add(-3 * time.Hour))},
+					},
+				}, nil)
+			})
+
+			It("should yield them in listing order", func() {
+				Expect(actualErr).To(BeNil())
+
+				ids := []string{}
+				for _, v := range versions {
+					ids = append(ids, v.VersionID)
+				}
+				Expect(ids).To(Equal([]string{"a1", "dm2", "b2", "dm1", "b1"}))
+			})
+
+			It("should keep the version metadata", func() {
+				Expect(versions[0].IsLatest).To(BeTrue())
+				Expect(versions[0].IsDeleteMarker).To(BeFalse())
+				Expect(versions[0].Size).To(Equal(int64(10)))
+				Expect(versions[1].IsDeleteMarker).To(BeTrue())
+				Expect(versions[1].IsLatest).To(BeTrue())
+			})
+		})
+
+		Context("when the listing spans several pages and prefixes", func() {
+			BeforeEach(func() {
+				fake := &fakeBucket{pageSize: 2}
+				for _, key := range []string{"a/1", "a/2", "a/3", "b/1", "c/1", "c/2", "c/3"} {
+					fake.addVersion(key, key+"-v")
+				}
+				fakeS3.ListObjectVersionsStub = fake.ListObjectVersions
+
+				prefixes = []string{"c/", "a/"}
+			})
+
+			It("should yield every version under the prefixes exactly once", func() {
+				Expect(actualErr).To(BeNil())
+
+				keys := []string{}
+				for _, v := range versions {
+					keys = append(keys, v.Key)
+				}
+				Expect(keys).To(Equal([]string{"a/1", "a/2", "a/3", "c/1", "c/2", "c/3"}))
+			})
+
+			It("should start each prefix without markers", func() {
+				Expect(fakeS3.ListObjectVersionsCallCount()).To(Equal(4))
+				Expect(fakeS3.ListObjectVersionsArgsForCall(2).Prefix).To(Equal(aws.String("c/")))
+				Expect(fakeS3.ListObjectVersionsArgsForCall(2).KeyMarker).To(BeNil())
+			})
+
+			Context("when starting after a version", func() {
+				BeforeEach(func() {
+					startAfter = &s3svc.Version{Key: "a/2", VersionID: "a/2-v"}
+				})
+
+				It("should continue right after it", func() {
+					keys := []string{}
+					for _, v := range versions {
+						keys = append(keys, v.Key)
+					}
+					Expect(keys).To(Equal([]string{"a/3", "c/1", "c/2", "c/3"}))
+					Expect(fakeS3.ListObjectVersionsArgsForCall(0).KeyMarker).To(Equal(aws.String("a/2")))
+					Expect(fakeS3.ListObjectVersionsArgsForCall(0).VersionIdMarker).To(Equal(aws.String("a/2-v")))
+				})
+			})
+
+			Context("when starting after a key between the prefixes", func() {
+				BeforeEach(func() {
+					startAfter = &s3svc.Version{Key: "b/1", VersionID: "b/1-v"}
+				})
+
+				It("should skip the prefixes before it", func() {
+					Expect(versions).To(HaveLen(3))
+					Expect(fakeS3.ListObjectVersionsArgsForCall(0).Prefix).To(Equal(aws.String("c/")))
+					Expect(fakeS3.ListObjectVersionsArgsForCall(0).KeyMarker).To(BeNil())
+				})
+			})
+
+			Context("when starting after the last prefix", func() {
+				BeforeEach(func() {
+					startAfter = &s3svc.Version{Key: "d/1", VersionID: "d/1-v"}
+				})
+
+				It("should yield nothing", func() {
+					Expect(actualErr).To(BeNil())
+					Expect(versions).To(BeEmpty())
+					Expect(fakeS3.ListObjectVersionsCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when a key has several versions across pages", func() {
+			BeforeEach(func() {
+				fake := &fakeBucket{pageSize: 1}
+				fake.addVersion("a", "a3")
+				fake.addDeleteMarker("a", "adm")
+				fake.addVersion("a", "a1")
+				fake.addVersion("b", "b1")
+				fakeS3.ListObjectVersionsStub = fake.ListObjectVersions
+			})
+
+			It("should record when each noncurrent version was replaced", func() {
+				Expect(versions).To(HaveLen(4))
+				Expect(versions[0].NoncurrentSince.IsZero()).To(BeTrue())
+				Expect(versions[1].NoncurrentSince).To(Equal(versions[0].LastModified))
+				Expect(versions[2].NoncurrentSince).To(Equal(versions[1].LastModified))
+				Expect(versions[3].NoncurrentSince.IsZero()).To(BeTrue())
+			})
+
+			Context("when resuming in the middle of the key", func() {
+				BeforeEach(func() {
+					startAfter = &s3svc.Version{Key: "a", VersionID: "adm"}
+				})
+
+				It("should not pick the rest of the key by when it was replaced", func() {
+					Expect(versions).To(HaveLen(2))
+					Expect(versions[0].VersionID).To(Equal("a1"))
+					Expect(versions[0].NoncurrentSince.IsZero()).To(BeTrue())
+
+					sinceNoncurrent := s3svc.OlderThan(time.Now().Add(time.Hour), true)
+					Expect(sinceNoncurrent(versions[0])).To(BeFalse())
+					Expect(sinceNoncurrent(versions[1])).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when a truncated response has no markers", func() {
+			BeforeEach(func() {
+				fakeS3.ListObjectVersionsReturns(&s3.ListObjectVersionsOutput{
+					IsTruncated: aws.Bool(true),
+					Versions: []*s3.ObjectVersion{
+						&s3.ObjectVersion{Key: aws.String("a"), VersionId: aws.String("a1")},
+					},
+				}, nil)
+			})
+
+			It("should stop instead of listing the same page again", func() {
+				Expect(fakeS3.ListObjectVersionsCallCount()).To(Equal(1))
+				Expect(versions).To(HaveLen(1))
+			})
+		})
+
+		Context("when s3.ListObjectVersions fails", func() {
+			BeforeEach(func() {
+				fakeS3.ListObjectVersionsReturns(nil, errors.New("fail"))
+			})
+
+			It("should stop and report the error", func() {
+				Expect(versions).To(BeEmpty())
+				Expect(actualErr).NotTo(BeNil())
+			})
+		})
+	})
 })
@@ -9,6 +9,45 @@ import (
 )
 
 type FakeAPI struct {
+	AbortMultipartUploadStub        func(*s3.AbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error)
+	abortMultipartUploadMutex       sync.RWMutex
+	abortMultipartUploadArgsForCall []struct {
+		arg1 *s3.AbortMultipartUploadInput
+	}
+	abortMultipartUploadReturns struct {
+		result1 *s3.AbortMultipartUploadOutput
+		result2 error
+	}
+	abortMultipartUploadReturnsOnCall map[int]struct {
+		result1 *s3.AbortMultipartUploadOutput
+		result2 error
+	}
+	DeleteBucketStub        func(*s3.DeleteBucketInput) (*s3.DeleteBucketOutput, error)
+	deleteBucketMutex       sync.RWMutex
+	deleteBucketArgsForCall []struct {
+		arg1 *s3.DeleteBucketInput
+	}
+	deleteBucketReturns struct {
+		result1 *s3.DeleteBucketOutput
+		result2 error
+	}
+	deleteBucketReturnsOnCall map[int]struct {
+		result1 *s3.DeleteBucketOutput
+		result2 error
+	}
+	DeleteBucketLifecycleStub        func(*s3.DeleteBucketLifecycleInput) (*s3.DeleteBucketLifecycleOutput, error)
+	deleteBucketLifecycleMutex       sync.RWMutex
+	deleteBucketLifecycleArgsForCall []struct {
+		arg1 *s3.DeleteBucketLifecycleInput
+	}
+	deleteBucketLifecycleReturns struct {
+		result1 *s3.DeleteBucketLifecycleOutput
+		result2 error
+	}
+	deleteBucketLifecycleReturnsOnCall map[int]struct {
+		result1 *s3.DeleteBucketLifecycleOutput
+		result2 error
+	}
 	DeleteObjectsStub        func(*s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error)
 	deleteObjectsMutex       sync.RWMutex
 	deleteObjectsArgsForCall []struct {
@@ -22,6 +61,71 @@ type FakeAPI struct {
 		result1 *s3.DeleteObjectsOutput
 		result2 error
 	}
+	GetBucketLifecycleConfigurationStub        func(*s3.GetBucketLifecycleConfigurationInput) (*s3.GetBucketLifecycleConfigurationOutput, error)
+	getBucketLifecycleConfigurationMutex       sync.RWMutex
+	getBucketLifecycleConfigurationArgsForCall []struct {
+		arg1 *s3.GetBucketLifecycleConfigurationInput
+	}
+	getBucketLifecycleConfigurationReturns struct {
+		result1 *s3.GetBucketLifecycleConfigurationOutput
+		result2 error
+	}
+	getBucketLifecycleConfigurationReturnsOnCall map[int]struct {
+		result1 *s3.GetBucketLifecycleConfigurationOutput
+		result2 error
+	}
+	GetBucketLocationStub        func(*s3.GetBucketLocationInput) (*s3.GetBucketLocationOutput, error)
+	getBucketLocationMutex       sync.RWMutex
+	getBucketLocationArgsForCall []struct {
+		arg1 *s3.GetBucketLocationInput
+	}
+	getBucketLocationReturns struct {
+		result1 *s3.GetBucketLocationOutput
+		result2 error
+	}
+	getBucketLocationReturnsOnCall map[int]struct {
+		result1 *s3.GetBucketLocationOutput
+		result2 error
+	}
+	GetObjectStub        func(*s3.GetObjectInput) (*s3.GetObjectOutput, error)
+	getObjectMutex       sync.RWMutex
+	getObjectArgsForCall []struct {
+		arg1 *s3.GetObjectInput
+	}
+	getObjectReturns struct {
+		result1 *s3.GetObjectOutput
+		result2 error
+	}
+	getObjectReturnsOnCall map[int]struct {
+		result1 *s3.GetObjectOutput
+		result2 error
+	}
+	ListBucketsStub        func(*s3.ListBucketsInput) (*s3.ListBucketsOutput, error)
+	listBucketsMutex       sync.RWMutex
+	listBucketsArgsForCall []struct {
+		arg1 *s3.ListBucketsInput
+	}
+	listBucketsReturns struct {
+		result1 *s3.ListBucketsOutput
+		result2 error
+	}
+	listBucketsReturnsOnCall map[int]struct {
+		result1 *s3.ListBucketsOutput
+		result2 error
+	}
+	ListMultipartUploadsStub        func(*s3.ListMultipartUploadsInput) (*s3.ListMultipartUploadsOutput, error)
+	listMultipartUploadsMutex       sync.RWMutex
+	listMultipartUploadsArgsForCall []struct {
+		arg1 *s3.ListMultipartUploadsInput
+	}
+	listMultipartUploadsReturns struct {
+		result1 *s3.ListMultipartUploadsOutput
+		result2 error
+	}
+	listMultipartUploadsReturnsOnCall map[int]struct {
+		result1 *s3.ListMultipartUploadsOutput
+		result2 error
+	}
 	ListObjectVersionsStub        func(*s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error)
 	listObjectVersionsMutex       sync.RWMutex
 	listObjectVersionsArgsForCall []struct {
@@ -35,10 +139,202 @@ type FakeAPI struct {
 		result1 *s3.ListObjectVersionsOutput
 		result2 error
 	}
+	ListObjectsV2Stub        func(*s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error)
+	listObjectsV2Mutex       sync.RWMutex
+	listObjectsV2ArgsForCall []struct {
+		arg1 *s3.ListObjectsV2Input
+	}
+	listObjectsV2Returns struct {
+		result1 *s3.ListObjectsV2Output
+		result2 error
+	}
+	listObjectsV2ReturnsOnCall map[int]struct {
+		result1 *s3.ListObjectsV2Output
+		result2 error
+	}
+	ListPartsStub        func(*s3.ListPartsInput) (*s3.ListPartsOutput, error)
+	listPartsMutex       sync.RWMutex
+	listPartsArgsForCall []struct {
+		arg1 *s3.ListPartsInput
+	}
+	listPartsReturns struct {
+		result1 *s3.ListPartsOutput
+		result2 error
+	}
+	listPartsReturnsOnCall map[int]struct {
+		result1 *s3.ListPartsOutput
+		result2 error
+	}
+	PutBucketLifecycleConfigurationStub        func(*s3.PutBucketLifecycleConfigurationInput) (*s3.PutBucketLifecycleConfigurationOutput, error)
+	putBucketLifecycleConfigurationMutex       sync.RWMutex
+	putBucketLifecycleConfigurationArgsForCall []struct {
+		arg1 *s3.PutBucketLifecycleConfigurationInput
+	}
+	putBucketLifecycleConfigurationReturns struct {
+		result1 *s3.PutBucketLifecycleConfigurationOutput
+		result2 error
+	}
+	putBucketLifecycleConfigurationReturnsOnCall map[int]struct {
+		result1 *s3.PutBucketLifecycleConfigurationOutput
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeAPI) AbortMultipartUpload(arg1 *s3.AbortMultipartUploadInput) (*s3.AbortMultipartUploadOutput, error) {
+	fake.abortMultipartUploadMutex.Lock()
+	ret, specificReturn := fake.abortMultipartUploadReturnsOnCall[len(fake.abortMultipartUploadArgsForCall)]
+	fake.abortMultipartUploadArgsForCall = append(fake.abortMultipartUploadArgsForCall, struct {
+		arg1 *s3.AbortMultipartUploadInput
+	}{arg1})
+	fake.recordInvocation("AbortMultipartUpload", []interface{}{arg1})
+	fake.abortMultipartUploadMutex.Unlock()
+	if fake.AbortMultipartUploadStub != nil {
+		return fake.AbortMultipartUploadStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.abortMultipartUploadReturns.result1, fake.abortMultipartUploadReturns.result2
+}
+
+func (fake *FakeAPI) AbortMultipartUploadCallCount() int {
+	fake.abortMultipartUploadMutex.RLock()
+	defer fake.abortMultipartUploadMutex.RUnlock()
+	return len(fake.abortMultipartUploadArgsForCall)
+}
+
+func (fake *FakeAPI) AbortMultipartUploadArgsForCall(i int) *s3.AbortMultipartUploadInput {
+	fake.abortMultipartUploadMutex.RLock()
+	defer fake.abortMultipartUploadMutex.RUnlock()
+	return fake.abortMultipartUploadArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) AbortMultipartUploadReturns(result1 *s3.AbortMultipartUploadOutput, result2 error) {
+	fake.AbortMultipartUploadStub = nil
+	fake.abortMultipartUploadReturns = struct {
+		result1 *s3.AbortMultipartUploadOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) AbortMultipartUploadReturnsOnCall(i int, result1 *s3.AbortMultipartUploadOutput, result2 error) {
+	fake.AbortMultipartUploadStub = nil
+	if fake.abortMultipartUploadReturnsOnCall == nil {
+		fake.abortMultipartUploadReturnsOnCall = make(map[int]struct {
+			result1 *s3.AbortMultipartUploadOutput
+			result2 error
+		})
+	}
+	fake.abortMultipartUploadReturnsOnCall[i] = struct {
+		result1 *s3.AbortMultipartUploadOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) DeleteBucket(arg1 *s3.DeleteBucketInput) (*s3.DeleteBucketOutput, error) {
+	fake.deleteBucketMutex.Lock()
+	ret, specificReturn := fake.deleteBucketReturnsOnCall[len(fake.deleteBucketArgsForCall)]
+	fake.deleteBucketArgsForCall = append(fake.deleteBucketArgsForCall, struct {
+		arg1 *s3.DeleteBucketInput
+	}{arg1})
+	fake.recordInvocation("DeleteBucket", []interface{}{arg1})
+	fake.deleteBucketMutex.Unlock()
+	if fake.DeleteBucketStub != nil {
+		return fake.DeleteBucketStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.deleteBucketReturns.result1, fake.deleteBucketReturns.result2
+}
+
+func (fake *FakeAPI) DeleteBucketCallCount() int {
+	fake.deleteBucketMutex.RLock()
+	defer fake.deleteBucketMutex.RUnlock()
+	return len(fake.deleteBucketArgsForCall)
+}
+
+func (fake *FakeAPI) DeleteBucketArgsForCall(i int) *s3.DeleteBucketInput {
+	fake.deleteBucketMutex.RLock()
+	defer fake.deleteBucketMutex.RUnlock()
+	return fake.deleteBucketArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) DeleteBucketReturns(result1 *s3.DeleteBucketOutput, result2 error) {
+	fake.DeleteBucketStub = nil
+	fake.deleteBucketReturns = struct {
+		result1 *s3.DeleteBucketOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) DeleteBucketReturnsOnCall(i int, result1 *s3.DeleteBucketOutput, result2 error) {
+	fake.DeleteBucketStub = nil
+	if fake.deleteBucketReturnsOnCall == nil {
+		fake.deleteBucketReturnsOnCall = make(map[int]struct {
+			result1 *s3.DeleteBucketOutput
+			result2 error
+		})
+	}
+	fake.deleteBucketReturnsOnCall[i] = struct {
+		result1 *s3.DeleteBucketOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) DeleteBucketLifecycle(arg1 *s3.DeleteBucketLifecycleInput) (*s3.DeleteBucketLifecycleOutput, error) {
+	fake.deleteBucketLifecycleMutex.Lock()
+	ret, specificReturn := fake.deleteBucketLifecycleReturnsOnCall[len(fake.deleteBucketLifecycleArgsForCall)]
+	fake.deleteBucketLifecycleArgsForCall = append(fake.deleteBucketLifecycleArgsForCall, struct {
+		arg1 *s3.DeleteBucketLifecycleInput
+	}{arg1})
+	fake.recordInvocation("DeleteBucketLifecycle", []interface{}{arg1})
+	fake.deleteBucketLifecycleMutex.Unlock()
+	if fake.DeleteBucketLifecycleStub != nil {
+		return fake.DeleteBucketLifecycleStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.deleteBucketLifecycleReturns.result1, fake.deleteBucketLifecycleReturns.result2
+}
+
+func (fake *FakeAPI) DeleteBucketLifecycleCallCount() int {
+	fake.deleteBucketLifecycleMutex.RLock()
+	defer fake.deleteBucketLifecycleMutex.RUnlock()
+	return len(fake.deleteBucketLifecycleArgsForCall)
+}
+
+func (fake *FakeAPI) DeleteBucketLifecycleArgsForCall(i int) *s3.DeleteBucketLifecycleInput {
+	fake.deleteBucketLifecycleMutex.RLock()
+	defer fake.deleteBucketLifecycleMutex.RUnlock()
+	return fake.deleteBucketLifecycleArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) DeleteBucketLifecycleReturns(result1 *s3.DeleteBucketLifecycleOutput, result2 error) {
+	fake.DeleteBucketLifecycleStub = nil
+	fake.deleteBucketLifecycleReturns = struct {
+		result1 *s3.DeleteBucketLifecycleOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) DeleteBucketLifecycleReturnsOnCall(i int, result1 *s3.DeleteBucketLifecycleOutput, result2 error) {
+	fake.DeleteBucketLifecycleStub = nil
+	if fake.deleteBucketLifecycleReturnsOnCall == nil {
+		fake.deleteBucketLifecycleReturnsOnCall = make(map[int]struct {
+			result1 *s3.DeleteBucketLifecycleOutput
+			result2 error
+		})
+	}
+	fake.deleteBucketLifecycleReturnsOnCall[i] = struct {
+		result1 *s3.DeleteBucketLifecycleOutput
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeAPI) DeleteObjects(arg1 *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
 	fake.deleteObjectsMutex.Lock()
 	ret, specificReturn := fake.deleteObjectsReturnsOnCall[len(fake.deleteObjectsArgsForCall)]
@@ -90,6 +386,261 @@ func (fake *FakeAPI) DeleteObjectsReturnsOnCall(i int, result1 *s3.DeleteObjects
 	}{result1, result2}
 }
 
+func (fake *FakeAPI) GetBucketLifecycleConfiguration(arg1 *s3.GetBucketLifecycleConfigurationInput) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	fake.getBucketLifecycleConfigurationMutex.Lock()
+	ret, specificReturn := fake.getBucketLifecycleConfigurationReturnsOnCall[len(fake.getBucketLifecycleConfigurationArgsForCall)]
+	fake.getBucketLifecycleConfigurationArgsForCall = append(fake.getBucketLifecycleConfigurationArgsForCall, struct {
+		arg1 *s3.GetBucketLifecycleConfigurationInput
+	}{arg1})
+	fake.recordInvocation("GetBucketLifecycleConfiguration", []interface{}{arg1})
+	fake.getBucketLifecycleConfigurationMutex.Unlock()
+	if fake.GetBucketLifecycleConfigurationStub != nil {
+		return fake.GetBucketLifecycleConfigurationStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getBucketLifecycleConfigurationReturns.result1, fake.getBucketLifecycleConfigurationReturns.result2
+}
+
+func (fake *FakeAPI) GetBucketLifecycleConfigurationCallCount() int {
+	fake.getBucketLifecycleConfigurationMutex.RLock()
+	defer fake.getBucketLifecycleConfigurationMutex.RUnlock()
+	return len(fake.getBucketLifecycleConfigurationArgsForCall)
+}
+
+func (fake *FakeAPI) GetBucketLifecycleConfigurationArgsForCall(i int) *s3.GetBucketLifecycleConfigurationInput {
+	fake.getBucketLifecycleConfigurationMutex.RLock()
+	defer fake.getBucketLifecycleConfigurationMutex.RUnlock()
+	return fake.getBucketLifecycleConfigurationArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) GetBucketLifecycleConfigurationReturns(result1 *s3.GetBucketLifecycleConfigurationOutput, result2 error) {
+	fake.GetBucketLifecycleConfigurationStub = nil
+	fake.getBucketLifecycleConfigurationReturns = struct {
+		result1 *s3.GetBucketLifecycleConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetBucketLifecycleConfigurationReturnsOnCall(i int, result1 *s3.GetBucketLifecycleConfigurationOutput, result2 error) {
+	fake.GetBucketLifecycleConfigurationStub = nil
+	if fake.getBucketLifecycleConfigurationReturnsOnCall == nil {
+		fake.getBucketLifecycleConfigurationReturnsOnCall = make(map[int]struct {
+			result1 *s3.GetBucketLifecycleConfigurationOutput
+			result2 error
+		})
+	}
+	fake.getBucketLifecycleConfigurationReturnsOnCall[i] = struct {
+		result1 *s3.GetBucketLifecycleConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetBucketLocation(arg1 *s3.GetBucketLocationInput) (*s3.GetBucketLocationOutput, error) {
+	fake.getBucketLocationMutex.Lock()
+	ret, specificReturn := fake.getBucketLocationReturnsOnCall[len(fake.getBucketLocationArgsForCall)]
+	fake.getBucketLocationArgsForCall = append(fake.getBucketLocationArgsForCall, struct {
+		arg1 *s3.GetBucketLocationInput
+	}{arg1})
+	fake.recordInvocation("GetBucketLocation", []interface{}{arg1})
+	fake.getBucketLocationMutex.Unlock()
+	if fake.GetBucketLocationStub != nil {
+		return fake.GetBucketLocationStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getBucketLocationReturns.result1, fake.getBucketLocationReturns.result2
+}
+
+func (fake *FakeAPI) GetBucketLocationCallCount() int {
+	fake.getBucketLocationMutex.RLock()
+	defer fake.getBucketLocationMutex.RUnlock()
+	return len(fake.getBucketLocationArgsForCall)
+}
+
+func (fake *FakeAPI) GetBucketLocationArgsForCall(i int) *s3.GetBucketLocationInput {
+	fake.getBucketLocationMutex.RLock()
+	defer fake.getBucketLocationMutex.RUnlock()
+	return fake.getBucketLocationArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) GetBucketLocationReturns(result1 *s3.GetBucketLocationOutput, result2 error) {
+	fake.GetBucketLocationStub = nil
+	fake.getBucketLocationReturns = struct {
+		result1 *s3.GetBucketLocationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetBucketLocationReturnsOnCall(i int, result1 *s3.GetBucketLocationOutput, result2 error) {
+	fake.GetBucketLocationStub = nil
+	if fake.getBucketLocationReturnsOnCall == nil {
+		fake.getBucketLocationReturnsOnCall = make(map[int]struct {
+			result1 *s3.GetBucketLocationOutput
+			result2 error
+		})
+	}
+	fake.getBucketLocationReturnsOnCall[i] = struct {
+		result1 *s3.GetBucketLocationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetObject(arg1 *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	fake.getObjectMutex.Lock()
+	ret, specificReturn := fake.getObjectReturnsOnCall[len(fake.getObjectArgsForCall)]
+	fake.getObjectArgsForCall = append(fake.getObjectArgsForCall, struct {
+		arg1 *s3.GetObjectInput
+	}{arg1})
+	fake.recordInvocation("GetObject", []interface{}{arg1})
+	fake.getObjectMutex.Unlock()
+	if fake.GetObjectStub != nil {
+		return fake.GetObjectStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.getObjectReturns.result1, fake.getObjectReturns.result2
+}
+
+func (fake *FakeAPI) GetObjectCallCount() int {
+	fake.getObjectMutex.RLock()
+	defer fake.getObjectMutex.RUnlock()
+	return len(fake.getObjectArgsForCall)
+}
+
+func (fake *FakeAPI) GetObjectArgsForCall(i int) *s3.GetObjectInput {
+	fake.getObjectMutex.RLock()
+	defer fake.getObjectMutex.RUnlock()
+	return fake.getObjectArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) GetObjectReturns(result1 *s3.GetObjectOutput, result2 error) {
+	fake.GetObjectStub = nil
+	fake.getObjectReturns = struct {
+		result1 *s3.GetObjectOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) GetObjectReturnsOnCall(i int, result1 *s3.GetObjectOutput, result2 error) {
+	fake.GetObjectStub = nil
+	if fake.getObjectReturnsOnCall == nil {
+		fake.getObjectReturnsOnCall = make(map[int]struct {
+			result1 *s3.GetObjectOutput
+			result2 error
+		})
+	}
+	fake.getObjectReturnsOnCall[i] = struct {
+		result1 *s3.GetObjectOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListBuckets(arg1 *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+	fake.listBucketsMutex.Lock()
+	ret, specificReturn := fake.listBucketsReturnsOnCall[len(fake.listBucketsArgsForCall)]
+	fake.listBucketsArgsForCall = append(fake.listBucketsArgsForCall, struct {
+		arg1 *s3.ListBucketsInput
+	}{arg1})
+	fake.recordInvocation("ListBuckets", []interface{}{arg1})
+	fake.listBucketsMutex.Unlock()
+	if fake.ListBucketsStub != nil {
+		return fake.ListBucketsStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listBucketsReturns.result1, fake.listBucketsReturns.result2
+}
+
+func (fake *FakeAPI) ListBucketsCallCount() int {
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	return len(fake.listBucketsArgsForCall)
+}
+
+func (fake *FakeAPI) ListBucketsArgsForCall(i int) *s3.ListBucketsInput {
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	return fake.listBucketsArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) ListBucketsReturns(result1 *s3.ListBucketsOutput, result2 error) {
+	fake.ListBucketsStub = nil
+	fake.listBucketsReturns = struct {
+		result1 *s3.ListBucketsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListBucketsReturnsOnCall(i int, result1 *s3.ListBucketsOutput, result2 error) {
+	fake.ListBucketsStub = nil
+	if fake.listBucketsReturnsOnCall == nil {
+		fake.listBucketsReturnsOnCall = make(map[int]struct {
+			result1 *s3.ListBucketsOutput
+			result2 error
+		})
+	}
+	fake.listBucketsReturnsOnCall[i] = struct {
+		result1 *s3.ListBucketsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListMultipartUploads(arg1 *s3.ListMultipartUploadsInput) (*s3.ListMultipartUploadsOutput, error) {
+	fake.listMultipartUploadsMutex.Lock()
+	ret, specificReturn := fake.listMultipartUploadsReturnsOnCall[len(fake.listMultipartUploadsArgsForCall)]
+	fake.listMultipartUploadsArgsForCall = append(fake.listMultipartUploadsArgsForCall, struct {
+		arg1 *s3.ListMultipartUploadsInput
+	}{arg1})
+	fake.recordInvocation("ListMultipartUploads", []interface{}{arg1})
+	fake.listMultipartUploadsMutex.Unlock()
+	if fake.ListMultipartUploadsStub != nil {
+		return fake.ListMultipartUploadsStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listMultipartUploadsReturns.result1, fake.listMultipartUploadsReturns.result2
+}
+
+func (fake *FakeAPI) ListMultipartUploadsCallCount() int {
+	fake.listMultipartUploadsMutex.RLock()
+	defer fake.listMultipartUploadsMutex.RUnlock()
+	return len(fake.listMultipartUploadsArgsForCall)
+}
+
+func (fake *FakeAPI) ListMultipartUploadsArgsForCall(i int) *s3.ListMultipartUploadsInput {
+	fake.listMultipartUploadsMutex.RLock()
+	defer fake.listMultipartUploadsMutex.RUnlock()
+	return fake.listMultipartUploadsArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) ListMultipartUploadsReturns(result1 *s3.ListMultipartUploadsOutput, result2 error) {
+	fake.ListMultipartUploadsStub = nil
+	fake.listMultipartUploadsReturns = struct {
+		result1 *s3.ListMultipartUploadsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListMultipartUploadsReturnsOnCall(i int, result1 *s3.ListMultipartUploadsOutput, result2 error) {
+	fake.ListMultipartUploadsStub = nil
+	if fake.listMultipartUploadsReturnsOnCall == nil {
+		fake.listMultipartUploadsReturnsOnCall = make(map[int]struct {
+			result1 *s3.ListMultipartUploadsOutput
+			result2 error
+		})
+	}
+	fake.listMultipartUploadsReturnsOnCall[i] = struct {
+		result1 *s3.ListMultipartUploadsOutput
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeAPI) ListObjectVersions(arg1 *s3.ListObjectVersionsInput) (*s3.ListObjectVersionsOutput, error) {
 	fake.listObjectVersionsMutex.Lock()
 	ret, specificReturn := fake.listObjectVersionsReturnsOnCall[len(fake.listObjectVersionsArgsForCall)]
@@ -141,13 +692,188 @@ func (fake *FakeAPI) ListObjectVersionsReturnsOnCall(i int, result1 *s3.ListObje
 	}{result1, result2}
 }
 
+func (fake *FakeAPI) ListObjectsV2(arg1 *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	fake.listObjectsV2Mutex.Lock()
+	ret, specificReturn := fake.listObjectsV2ReturnsOnCall[len(fake.listObjectsV2ArgsForCall)]
+	fake.listObjectsV2ArgsForCall = append(fake.listObjectsV2ArgsForCall, struct {
+		arg1 *s3.ListObjectsV2Input
+	}{arg1})
+	fake.recordInvocation("ListObjectsV2", []interface{}{arg1})
+	fake.listObjectsV2Mutex.Unlock()
+	if fake.ListObjectsV2Stub != nil {
+		return fake.ListObjectsV2Stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listObjectsV2Returns.result1, fake.listObjectsV2Returns.result2
+}
+
+func (fake *FakeAPI) ListObjectsV2CallCount() int {
+	fake.listObjectsV2Mutex.RLock()
+	defer fake.listObjectsV2Mutex.RUnlock()
+	return len(fake.listObjectsV2ArgsForCall)
+}
+
+func (fake *FakeAPI) ListObjectsV2ArgsForCall(i int) *s3.ListObjectsV2Input {
+	fake.listObjectsV2Mutex.RLock()
+	defer fake.listObjectsV2Mutex.RUnlock()
+	return fake.listObjectsV2ArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) ListObjectsV2Returns(result1 *s3.ListObjectsV2Output, result2 error) {
+	fake.ListObjectsV2Stub = nil
+	fake.listObjectsV2Returns = struct {
+		result1 *s3.ListObjectsV2Output
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListObjectsV2ReturnsOnCall(i int, result1 *s3.ListObjectsV2Output, result2 error) {
+	fake.ListObjectsV2Stub = nil
+	if fake.listObjectsV2ReturnsOnCall == nil {
+		fake.listObjectsV2ReturnsOnCall = make(map[int]struct {
+			result1 *s3.ListObjectsV2Output
+			result2 error
+		})
+	}
+	fake.listObjectsV2ReturnsOnCall[i] = struct {
+		result1 *s3.ListObjectsV2Output
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListParts(arg1 *s3.ListPartsInput) (*s3.ListPartsOutput, error) {
+	fake.listPartsMutex.Lock()
+	ret, specificReturn := fake.listPartsReturnsOnCall[len(fake.listPartsArgsForCall)]
+	fake.listPartsArgsForCall = append(fake.listPartsArgsForCall, struct {
+		arg1 *s3.ListPartsInput
+	}{arg1})
+	fake.recordInvocation("ListParts", []interface{}{arg1})
+	fake.listPartsMutex.Unlock()
+	if fake.ListPartsStub != nil {
+		return fake.ListPartsStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listPartsReturns.result1, fake.listPartsReturns.result2
+}
+
+func (fake *FakeAPI) ListPartsCallCount() int {
+	fake.listPartsMutex.RLock()
+	defer fake.listPartsMutex.RUnlock()
+	return len(fake.listPartsArgsForCall)
+}
+
+func (fake *FakeAPI) ListPartsArgsForCall(i int) *s3.ListPartsInput {
+	fake.listPartsMutex.RLock()
+	defer fake.listPartsMutex.RUnlock()
+	return fake.listPartsArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) ListPartsReturns(result1 *s3.ListPartsOutput, result2 error) {
+	fake.ListPartsStub = nil
+	fake.listPartsReturns = struct {
+		result1 *s3.ListPartsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) ListPartsReturnsOnCall(i int, result1 *s3.ListPartsOutput, result2 error) {
+	fake.ListPartsStub = nil
+	if fake.listPartsReturnsOnCall == nil {
+		fake.listPartsReturnsOnCall = make(map[int]struct {
+			result1 *s3.ListPartsOutput
+			result2 error
+		})
+	}
+	fake.listPartsReturnsOnCall[i] = struct {
+		result1 *s3.ListPartsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) PutBucketLifecycleConfiguration(arg1 *s3.PutBucketLifecycleConfigurationInput) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	fake.putBucketLifecycleConfigurationMutex.Lock()
+	ret, specificReturn := fake.putBucketLifecycleConfigurationReturnsOnCall[len(fake.putBucketLifecycleConfigurationArgsForCall)]
+	fake.putBucketLifecycleConfigurationArgsForCall = append(fake.putBucketLifecycleConfigurationArgsForCall, struct {
+		arg1 *s3.PutBucketLifecycleConfigurationInput
+	}{arg1})
+	fake.recordInvocation("PutBucketLifecycleConfiguration", []interface{}{arg1})
+	fake.putBucketLifecycleConfigurationMutex.Unlock()
+	if fake.PutBucketLifecycleConfigurationStub != nil {
+		return fake.PutBucketLifecycleConfigurationStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.putBucketLifecycleConfigurationReturns.result1, fake.putBucketLifecycleConfigurationReturns.result2
+}
+
+func (fake *FakeAPI) PutBucketLifecycleConfigurationCallCount() int {
+	fake.putBucketLifecycleConfigurationMutex.RLock()
+	defer fake.putBucketLifecycleConfigurationMutex.RUnlock()
+	return len(fake.putBucketLifecycleConfigurationArgsForCall)
+}
+
+func (fake *FakeAPI) PutBucketLifecycleConfigurationArgsForCall(i int) *s3.PutBucketLifecycleConfigurationInput {
+	fake.putBucketLifecycleConfigurationMutex.RLock()
+	defer fake.putBucketLifecycleConfigurationMutex.RUnlock()
+	return fake.putBucketLifecycleConfigurationArgsForCall[i].arg1
+}
+
+func (fake *FakeAPI) PutBucketLifecycleConfigurationReturns(result1 *s3.PutBucketLifecycleConfigurationOutput, result2 error) {
+	fake.PutBucketLifecycleConfigurationStub = nil
+	fake.putBucketLifecycleConfigurationReturns = struct {
+		result1 *s3.PutBucketLifecycleConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAPI) PutBucketLifecycleConfigurationReturnsOnCall(i int, result1 *s3.PutBucketLifecycleConfigurationOutput, result2 error) {
+	fake.PutBucketLifecycleConfigurationStub = nil
+	if fake.putBucketLifecycleConfigurationReturnsOnCall == nil {
+		fake.putBucketLifecycleConfigurationReturnsOnCall = make(map[int]struct {
+			result1 *s3.PutBucketLifecycleConfigurationOutput
+			result2 error
+		})
+	}
+	fake.putBucketLifecycleConfigurationReturnsOnCall[i] = struct {
+		result1 *s3.PutBucketLifecycleConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeAPI) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.abortMultipartUploadMutex.RLock()
+	defer fake.abortMultipartUploadMutex.RUnlock()
+	fake.deleteBucketMutex.RLock()
+	defer fake.deleteBucketMutex.RUnlock()
+	fake.deleteBucketLifecycleMutex.RLock()
+	defer fake.deleteBucketLifecycleMutex.RUnlock()
 	fake.deleteObjectsMutex.RLock()
 	defer fake.deleteObjectsMutex.RUnlock()
+	fake.getBucketLifecycleConfigurationMutex.RLock()
+	defer fake.getBucketLifecycleConfigurationMutex.RUnlock()
+	fake.getBucketLocationMutex.RLock()
+	defer fake.getBucketLocationMutex.RUnlock()
+	fake.getObjectMutex.RLock()
+	defer fake.getObjectMutex.RUnlock()
+	fake.listBucketsMutex.RLock()
+	defer fake.listBucketsMutex.RUnlock()
+	fake.listMultipartUploadsMutex.RLock()
+	defer fake.listMultipartUploadsMutex.RUnlock()
 	fake.listObjectVersionsMutex.RLock()
 	defer fake.listObjectVersionsMutex.RUnlock()
+	fake.listObjectsV2Mutex.RLock()
+	defer fake.listObjectsV2Mutex.RUnlock()
+	fake.listPartsMutex.RLock()
+	defer fake.listPartsMutex.RUnlock()
+	fake.putBucketLifecycleConfigurationMutex.RLock()
+	defer fake.putBucketLifecycleConfigurationMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
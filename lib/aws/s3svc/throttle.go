@@ -0,0 +1,257 @@
+package s3svc
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// throttle limits the calls a Client has in flight. The limit starts at the client's concurrency,
+// is halved whenever S3 throttles a call and grows back by one per limit's worth of calls that
+// go through (additive increase, multiplicative decrease). An optional rate caps how many calls
+// start per second on top of that.
+type throttle struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	max      int
+	limit    float64
+	inFlight int
+
+	// epoch counts the decreases, a throttled call only cuts the limit when no other call has
+	// done so since it started, so a burst of SlowDowns from one window halves it once
+	epoch int
+
+	interval time.Duration
+	next     time.Time
+}
+
+func newThrottle(max int, rps float64) *throttle {
+	t := &throttle{max: max, limit: float64(max)}
+	t.cond = sync.NewCond(&t.mu)
+
+	if rps > 0 {
+		t.interval = time.Duration(float64(time.Second) / rps)
+	}
+
+	return t
+}
+
+// acquire blocks until another call may start, and returns the epoch to hand to release.
+func (t *throttle) acquire() int {
+	t.mu.Lock()
+
+	for t.inFlight >= int(t.limit) {
+		t.cond.Wait()
+	}
+	t.inFlight++
+	epoch := t.epoch
+
+	var wait time.Duration
+	if t.interval > 0 {
+		now := time.Now()
+		if t.next.Before(now) {
+			t.next = now
+		}
+		wait = t.next.Sub(now)
+		t.next = t.next.Add(t.interval)
+	}
+
+	t.mu.Unlock()
+
+	time.Sleep(wait)
+	return epoch
+}
+
+// release ends a call that started in epoch and adjusts the limit by whether S3 throttled it.
+func (t *throttle) release(epoch int, throttled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inFlight--
+
+	switch {
+	case throttled && epoch == t.epoch:
+		t.limit = math.Max(1, t.limit/2)
+		t.epoch++
+	case !throttled && t.limit < float64(t.max):
+		t.limit = math.Min(float64(t.max), t.limit+1/t.limit)
+	}
+
+	t.cond.Broadcast()
+}
+
+// Limit returns how many calls may currently be in flight.
+func (t *throttle) Limit() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return int(t.limit)
+}
+
+// isThrottle reports whether S3 turned a request down because it's sending too many.
+func isThrottle(err error) bool {
+	if request.IsErrorThrottle(err) {
+		return true
+	}
+
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusServiceUnavailable {
+		return true
+	}
+
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == "SlowDown"
+}
+
+// throttledAPI passes every call through a throttle. Calls that S3 throttles are tried again with
+// the client's retry backoff, after the SDK's own retries have given up.
+type throttledAPI struct {
+	api API
+	t   *throttle
+
+	attempts int
+	backoff  time.Duration
+}
+
+// do runs call through the throttle, calling it again while it fails with a throttling error.
+// DeleteObjects reports SlowDown per key, which counts as throttled too but is retried by the caller.
+func (a *throttledAPI) do(call func() (keysThrottled bool, err error)) error {
+	for attempt := 0; ; attempt++ {
+		epoch := a.t.acquire()
+		keysThrottled, err := call()
+		throttled := keysThrottled || isThrottle(err)
+		a.t.release(epoch, throttled)
+
+		if err == nil || !isThrottle(err) || attempt+1 >= a.attempts {
+			return err
+		}
+
+		time.Sleep(a.backoff << uint(attempt))
+	}
+}
+
+// throttled runs a call that isn't part of API, like the ones of LockAPI, through the same throttle.
+func (c *Client) throttled(call func() error) error {
+	return c.s3api.(*throttledAPI).do(func() (bool, error) {
+		return false, call()
+	})
+}
+
+func (a *throttledAPI) AbortMultipartUpload(input *s3.AbortMultipartUploadInput) (out *s3.AbortMultipartUploadOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.AbortMultipartUpload(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) DeleteBucket(input *s3.DeleteBucketInput) (out *s3.DeleteBucketOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.DeleteBucket(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) DeleteBucketLifecycle(input *s3.DeleteBucketLifecycleInput) (out *s3.DeleteBucketLifecycleOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.DeleteBucketLifecycle(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) DeleteObjects(input *s3.DeleteObjectsInput) (out *s3.DeleteObjectsOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.DeleteObjects(input)
+		if err != nil {
+			return false, err
+		}
+
+		for _, keyErr := range out.Errors {
+			if aws.StringValue(keyErr.Code) == "SlowDown" {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return out, err
+}
+
+func (a *throttledAPI) GetBucketLifecycleConfiguration(input *s3.GetBucketLifecycleConfigurationInput) (out *s3.GetBucketLifecycleConfigurationOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.GetBucketLifecycleConfiguration(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) GetBucketLocation(input *s3.GetBucketLocationInput) (out *s3.GetBucketLocationOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.GetBucketLocation(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) GetObject(input *s3.GetObjectInput) (out *s3.GetObjectOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.GetObject(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) ListBuckets(input *s3.ListBucketsInput) (out *s3.ListBucketsOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.ListBuckets(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) ListMultipartUploads(input *s3.ListMultipartUploadsInput) (out *s3.ListMultipartUploadsOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.ListMultipartUploads(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) ListObjectVersions(input *s3.ListObjectVersionsInput) (out *s3.ListObjectVersionsOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.ListObjectVersions(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) ListObjectsV2(input *s3.ListObjectsV2Input) (out *s3.ListObjectsV2Output, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.ListObjectsV2(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) ListParts(input *s3.ListPartsInput) (out *s3.ListPartsOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.ListParts(input)
+		return false, err
+	})
+	return out, err
+}
+
+func (a *throttledAPI) PutBucketLifecycleConfiguration(input *s3.PutBucketLifecycleConfigurationInput) (out *s3.PutBucketLifecycleConfigurationOutput, err error) {
+	err = a.do(func() (bool, error) {
+		out, err = a.api.PutBucketLifecycleConfiguration(input)
+		return false, err
+	})
+	return out, err
+}
@@ -0,0 +1,182 @@
+package s3svc_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc/s3svcfakes"
+)
+
+var _ = Describe("Throttling", func() {
+	var (
+		fake   *fakeBucket
+		fakeS3 *s3svcfakes.FakeAPI
+		client *s3svc.Client
+
+		slowDown = awserr.NewRequestFailure(awserr.New("SlowDown", "Please reduce your request rate.", nil), 503, "request-id")
+	)
+
+	BeforeEach(func() {
+		fake = &fakeBucket{}
+		fake.addVersion("a", "a1")
+		fakeS3 = newFakeS3(fake)
+		client = s3svc.NewClient(fakeS3, false, s3svc.WithConcurrency(8), s3svc.WithRetries(5, time.Millisecond))
+	})
+
+	deleteOne := func() (*s3svc.DeleteResult, error) {
+		return client.DeleteObjects("bucket", []*s3.ObjectIdentifier{{Key: aws.String("a"), VersionId: aws.String("a1")}})
+	}
+
+	It("should start at the full concurrency", func() {
+		Expect(client.ConcurrencyLimit()).To(Equal(8))
+	})
+
+	Context("when S3 throttles a few requests", func() {
+		BeforeEach(func() {
+			fakeS3.DeleteObjectsStub = func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				if fakeS3.DeleteObjectsCallCount() <= 2 {
+					return nil, slowDown
+				}
+
+				return fake.DeleteObjects(input)
+			}
+		})
+
+		It("should try them again and halve the limit each time", func() {
+			result, err := deleteOne()
+			Expect(err).To(BeNil())
+			Expect(result.Deleted).To(HaveLen(1))
+			Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(3))
+			Expect(client.ConcurrencyLimit()).To(Equal(2))
+		})
+
+		It("should ramp back up to the full concurrency once S3 stops throttling", func() {
+			_, err := deleteOne()
+			Expect(err).To(BeNil())
+
+			for i := 0; i < 40; i++ {
+				_, err := client.DeleteObjects("bucket", []*s3.ObjectIdentifier{})
+				Expect(err).To(BeNil())
+			}
+			Expect(client.ConcurrencyLimit()).To(Equal(8))
+		})
+	})
+
+	Context("when S3 keeps throttling", func() {
+		BeforeEach(func() {
+			fakeS3.DeleteObjectsStub = nil
+			fakeS3.DeleteObjectsReturns(nil, slowDown)
+		})
+
+		It("should give up after the retries and bottom out at one request at a time", func() {
+			_, err := deleteOne()
+			Expect(err).NotTo(BeNil())
+			Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(5))
+			Expect(client.ConcurrencyLimit()).To(Equal(1))
+		})
+	})
+
+	Context("when S3 reports SlowDown for single keys", func() {
+		BeforeEach(func() {
+			fakeS3.DeleteObjectsStub = func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				if fakeS3.DeleteObjectsCallCount() == 1 {
+					return &s3.DeleteObjectsOutput{
+						Errors: []*s3.Error{{Code: aws.String("SlowDown"), Key: aws.String("a"), VersionId: aws.String("a1")}},
+					}, nil
+				}
+
+				return fake.DeleteObjects(input)
+			}
+		})
+
+		It("should cut the limit too", func() {
+			result, err := deleteOne()
+			Expect(err).To(BeNil())
+			Expect(result.Deleted).To(HaveLen(1))
+			Expect(client.ConcurrencyLimit()).To(Equal(4))
+		})
+	})
+
+	Context("when a request fails for another reason", func() {
+		BeforeEach(func() {
+			fakeS3.DeleteObjectsStub = nil
+			fakeS3.DeleteObjectsReturns(nil, errors.New("AccessDenied"))
+		})
+
+		It("should neither retry it nor cut the limit", func() {
+			_, err := deleteOne()
+			Expect(err).NotTo(BeNil())
+			Expect(fakeS3.DeleteObjectsCallCount()).To(Equal(1))
+			Expect(client.ConcurrencyLimit()).To(Equal(8))
+		})
+	})
+
+	Context("when many workers are throttled at once", func() {
+		var (
+			mu          sync.Mutex
+			inFlight    int
+			maxInFlight int
+		)
+
+		BeforeEach(func() {
+			inFlight, maxInFlight = 0, 0
+			for i := 0; i < 400; i++ {
+				fake.addVersion(fmt.Sprintf("key-%03d", i), fmt.Sprintf("v%03d", i))
+			}
+
+			fakeS3.DeleteObjectsStub = func(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				throttled := fakeS3.DeleteObjectsCallCount() <= 8
+				mu.Unlock()
+
+				time.Sleep(2 * time.Millisecond)
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+
+				if throttled {
+					return nil, slowDown
+				}
+				return fake.DeleteObjects(input)
+			}
+			client = s3svc.NewClient(fakeS3, false, s3svc.WithConcurrency(8), s3svc.WithBatchSize(10), s3svc.WithRetries(5, time.Millisecond))
+		})
+
+		It("should still empty the bucket without going over the concurrency", func() {
+			_, err := client.DeleteBucketContents("bucket")
+			Expect(err).To(BeNil())
+			Expect(fake.remaining()).To(BeEmpty())
+			Expect(maxInFlight).To(BeNumerically("<=", 8))
+		})
+	})
+
+	Context("with a maximum request rate", func() {
+		BeforeEach(func() {
+			client = s3svc.NewClient(fakeS3, false, s3svc.WithMaxRPS(100))
+		})
+
+		It("should space the requests out", func() {
+			start := time.Now()
+			for i := 0; i < 11; i++ {
+				_, err := client.DeleteObjects("bucket", []*s3.ObjectIdentifier{})
+				Expect(err).To(BeNil())
+			}
+			Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		})
+	})
+})
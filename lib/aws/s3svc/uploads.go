@@ -0,0 +1,187 @@
+package s3svc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// Upload is an incomplete multipart upload. Its parts are stored, and billed, until it is completed
+// or aborted, but they never show up in a version listing.
+type Upload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+
+	// Parts and Size are only filled in by AddPartSizes.
+	Parts int
+	Size  int64
+}
+
+// AbortFailure describes a multipart upload that AbortUploads could not abort.
+type AbortFailure struct {
+	Upload *Upload
+	Err    error
+}
+
+// AbortResult holds the per-upload outcome of aborting multipart uploads.
+type AbortResult struct {
+	Aborted []*Upload
+	Failed  []*AbortFailure
+}
+
+// ListUploads returns the multipart uploads in progress in the specified s3 bucket whose keys begin
+// with one of the prefixes, or every upload in the bucket if none are given.
+func (c *Client) ListUploads(bucket string, prefixes ...string) ([]*Upload, error) {
+	uploads := []*Upload{}
+
+	for _, prefix := range NormalizePrefixes(prefixes) {
+		input := &s3.ListMultipartUploadsInput{Bucket: aws.String(bucket)}
+		if prefix != "" {
+			input.Prefix = aws.String(prefix)
+		}
+
+		for {
+			resp, err := c.s3api.ListMultipartUploads(input)
+			if err != nil {
+				return nil, errors.Wrap(err, "package: s3svc => method: ListUploads => method call s3api.ListMultipartUploads failed\n")
+			}
+
+			for _, upload := range resp.Uploads {
+				uploads = append(uploads, &Upload{
+					Key:       aws.StringValue(upload.Key),
+					UploadID:  aws.StringValue(upload.UploadId),
+					Initiated: aws.TimeValue(upload.Initiated),
+				})
+			}
+
+			// same guard as VersionIterator.fetch, a truncated page without markers would loop forever
+			if !aws.BoolValue(resp.IsTruncated) || resp.NextKeyMarker == nil {
+				break
+			}
+
+			input = &s3.ListMultipartUploadsInput{
+				Bucket:         input.Bucket,
+				Prefix:         input.Prefix,
+				KeyMarker:      resp.NextKeyMarker,
+				UploadIdMarker: resp.NextUploadIdMarker,
+			}
+		}
+	}
+
+	return uploads, nil
+}
+
+// AddPartSizes lists the parts of each multipart upload and fills in how many there are and their total
+// size, running as many ListParts calls in parallel as the client's concurrency allows.
+func (c *Client) AddPartSizes(bucket string, uploads []*Upload) error {
+	errs := make([]error, len(uploads))
+
+	c.each(len(uploads), func(i int) {
+		errs[i] = c.addPartSize(bucket, uploads[i])
+	})
+
+	// report the first failure in listing order so the error does not depend on scheduling
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) addPartSize(bucket string, upload *Upload) error {
+	upload.Parts, upload.Size = 0, 0
+
+	input := &s3.ListPartsInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(upload.Key),
+		UploadId: aws.String(upload.UploadID),
+	}
+
+	for {
+		resp, err := c.s3api.ListParts(input)
+		if err != nil {
+			return errors.Wrapf(err, "package: s3svc => method: AddPartSizes => method call s3api.ListParts failed for %s\n", upload.Key)
+		}
+
+		for _, part := range resp.Parts {
+			upload.Parts++
+			upload.Size += aws.Int64Value(part.Size)
+		}
+
+		if !aws.BoolValue(resp.IsTruncated) || resp.NextPartNumberMarker == nil {
+			return nil
+		}
+
+		input = &s3.ListPartsInput{
+			Bucket:           input.Bucket,
+			Key:              input.Key,
+			UploadId:         input.UploadId,
+			PartNumberMarker: resp.NextPartNumberMarker,
+		}
+	}
+}
+
+// AbortUploads aborts each of the multipart uploads in the specified s3 bucket, which removes their
+// parts, running as many AbortMultipartUpload calls in parallel as the client's concurrency allows.
+// Uploads that could not be aborted are reported in AbortResult.Failed, both lists keep the order
+// of uploads.
+func (c *Client) AbortUploads(bucket string, uploads []*Upload) *AbortResult {
+	errs := make([]error, len(uploads))
+
+	c.each(len(uploads), func(i int) {
+		_, err := c.s3api.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(uploads[i].Key),
+			UploadId: aws.String(uploads[i].UploadID),
+		})
+		if err != nil {
+			errs[i] = errors.Wrap(err, "package: s3svc => method: AbortUploads => method call s3api.AbortMultipartUpload failed\n")
+		}
+	})
+
+	result := &AbortResult{
+		Aborted: []*Upload{},
+		Failed:  []*AbortFailure{},
+	}
+	for i, err := range errs {
+		if err != nil {
+			result.Failed = append(result.Failed, &AbortFailure{Upload: uploads[i], Err: err})
+			continue
+		}
+
+		result.Aborted = append(result.Aborted, uploads[i])
+	}
+
+	return result
+}
+
+// each calls fn once for every index below n from a pool of goroutines sized by the client's
+// concurrency, and returns when all calls have.
+func (c *Client) each(n int, fn func(i int)) {
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < c.concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+}
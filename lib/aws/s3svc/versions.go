@@ -0,0 +1,249 @@
+package s3svc
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// Version is a single object version or delete marker from a bucket's version listing.
+type Version struct {
+	Key            string
+	VersionID      string
+	IsDeleteMarker bool
+	IsLatest       bool
+	Size           int64
+	LastModified   time.Time
+
+	// NoncurrentSince is when a newer version or delete marker replaced this one, the zero time for
+	// the latest entry of a key or when the newer entry was not part of the listing.
+	NoncurrentSince time.Time
+}
+
+// ObjectIdentifier returns the identifier DeleteObjects needs to remove this version. A version
+// without an ID stands for the key itself, which leaves a delete marker in a versioned bucket.
+func (v *Version) ObjectIdentifier() *s3.ObjectIdentifier {
+	id := &s3.ObjectIdentifier{Key: aws.String(v.Key)}
+	if v.VersionID != "" {
+		id.VersionId = aws.String(v.VersionID)
+	}
+
+	return id
+}
+
+// VersionSource yields versions and delete markers one at a time in listing order, by key and then newest
+// first. VersionIterator reads them from a live listing, FilterVersions and FilterKeys narrow down another source.
+type VersionSource interface {
+	// Next advances to the next version, returning false when there are no more or an error occurred.
+	Next() bool
+
+	// Version returns the version the source is positioned at by the last call to Next.
+	Version() *Version
+
+	// Err returns the error that stopped the source, if any.
+	Err() error
+}
+
+// CountVersions reads at most limit versions from source and returns how many it read, and whether
+// the source had more left.
+func CountVersions(source VersionSource, limit int) (int, bool, error) {
+	count := 0
+	for count < limit && source.Next() {
+		count++
+	}
+
+	more := count == limit && source.Next()
+
+	if err := source.Err(); err != nil {
+		return 0, false, errors.Wrap(err, "package: s3svc => func: CountVersions => method call s3svc.VersionSource.Next failed\n")
+	}
+
+	return count, more, nil
+}
+
+// VersionIterator pages through the versions and delete markers under a set of prefixes, following
+// the key and version id markers S3 returns, and yields them one at a time in listing order: by key,
+// then newest first. Use it like a bufio.Scanner:
+//
+//	it := client.NewVersionIterator(bucket, prefixes...)
+//	for it.Next() {
+//		v := it.Version()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type VersionIterator struct {
+	c        *Client
+	bucket   string
+	prefixes []string
+
+	keyMarker       *string
+	versionIDMarker *string
+	lastPage        bool
+
+	page    []*Version
+	current *Version
+	err     error
+
+	// previous is the version before current, it's newer when it has the same key
+	previous *Version
+}
+
+// NewVersionIterator returns an iterator over the versions and delete markers in the specified
+// s3 bucket whose keys begin with one of the prefixes, or over the whole bucket if none are given.
+func (c *Client) NewVersionIterator(bucket string, prefixes ...string) *VersionIterator {
+	return &VersionIterator{
+		c:        c,
+		bucket:   bucket,
+		prefixes: NormalizePrefixes(prefixes),
+	}
+}
+
+// StartAfter makes the iterator skip everything up to and including the version of key with the
+// given id, as if a previous listing had stopped there. It must be called before the first Next.
+// Prefixes that sort entirely before key are dropped.
+func (it *VersionIterator) StartAfter(key, versionID string) {
+	if key == "" {
+		return
+	}
+
+	for len(it.prefixes) > 1 && it.prefixes[0] < key && !strings.HasPrefix(key, it.prefixes[0]) {
+		it.prefixes = it.prefixes[1:]
+	}
+
+	// a key before the remaining prefix needs no marker, one after it leaves nothing to list
+	if !strings.HasPrefix(key, it.prefixes[0]) {
+		if key > it.prefixes[0] {
+			it.lastPage = true
+		}
+		return
+	}
+
+	it.keyMarker = aws.String(key)
+	if versionID != "" {
+		it.versionIDMarker = aws.String(versionID)
+	}
+}
+
+// Next advances to the next version, fetching another page when needed. It returns false when the
+// listing is exhausted or a call to ListObjectVersions failed, check Err to tell them apart.
+func (it *VersionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil {
+			return false
+		}
+
+		if it.lastPage {
+			// normalized prefixes are sorted and never overlap, so the next one
+			// starts a fresh listing
+			if len(it.prefixes) <= 1 {
+				return false
+			}
+
+			it.prefixes = it.prefixes[1:]
+			it.keyMarker, it.versionIDMarker, it.lastPage = nil, nil, false
+		}
+
+		it.fetch()
+	}
+
+	it.previous = it.current
+	it.current, it.page = it.page[0], it.page[1:]
+
+	if it.previous != nil && it.previous.Key == it.current.Key && !it.current.IsLatest {
+		it.current.NoncurrentSince = it.previous.LastModified
+	}
+
+	return true
+}
+
+// Version returns the version the iterator is positioned at by the last call to Next.
+func (it *VersionIterator) Version() *Version {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *VersionIterator) Err() error {
+	return it.err
+}
+
+func (it *VersionIterator) fetch() {
+	input := &s3.ListObjectVersionsInput{
+		Bucket:          aws.String(it.bucket),
+		KeyMarker:       it.keyMarker,
+		VersionIdMarker: it.versionIDMarker,
+	}
+	if it.prefixes[0] != "" {
+		input.Prefix = aws.String(it.prefixes[0])
+	}
+
+	resp, err := it.c.s3api.ListObjectVersions(input)
+	if err != nil {
+		it.err = errors.Wrap(err, "package: s3svc => method: VersionIterator.fetch => method call s3api.ListObjectVersions failed\n")
+		return
+	}
+
+	it.page = mergeVersions(resp)
+
+	// guard against a truncated response without markers, which would otherwise list the same page forever
+	if !aws.BoolValue(resp.IsTruncated) || resp.NextKeyMarker == nil {
+		it.lastPage = true
+		return
+	}
+
+	it.keyMarker = resp.NextKeyMarker
+	it.versionIDMarker = resp.NextVersionIdMarker
+}
+
+// mergeVersions combines the versions and delete markers of a page, which S3 returns as two separately
+// sorted lists, into a single list in listing order.
+func mergeVersions(resp *s3.ListObjectVersionsOutput) []*Version {
+	merged := make([]*Version, 0, len(resp.Versions)+len(resp.DeleteMarkers))
+
+	versions, markers := resp.Versions, resp.DeleteMarkers
+	for len(versions) > 0 || len(markers) > 0 {
+		if len(markers) == 0 || (len(versions) > 0 && versionFirst(versions[0], markers[0])) {
+			obj := versions[0]
+			versions = versions[1:]
+
+			merged = append(merged, &Version{
+				Key:          aws.StringValue(obj.Key),
+				VersionID:    aws.StringValue(obj.VersionId),
+				IsLatest:     aws.BoolValue(obj.IsLatest),
+				Size:         aws.Int64Value(obj.Size),
+				LastModified: aws.TimeValue(obj.LastModified),
+			})
+			continue
+		}
+
+		obj := markers[0]
+		markers = markers[1:]
+
+		merged = append(merged, &Version{
+			Key:            aws.StringValue(obj.Key),
+			VersionID:      aws.StringValue(obj.VersionId),
+			IsDeleteMarker: true,
+			IsLatest:       aws.BoolValue(obj.IsLatest),
+			LastModified:   aws.TimeValue(obj.LastModified),
+		})
+	}
+
+	return merged
+}
+
+// versionFirst reports whether the version is listed ahead of the delete marker.
+func versionFirst(version *s3.ObjectVersion, marker *s3.DeleteMarkerEntry) bool {
+	versionKey, markerKey := aws.StringValue(version.Key), aws.StringValue(marker.Key)
+	if versionKey != markerKey {
+		return versionKey < markerKey
+	}
+
+	// the latest entry for a key always comes first, after that it's newest first
+	if aws.BoolValue(version.IsLatest) || aws.BoolValue(marker.IsLatest) {
+		return aws.BoolValue(version.IsLatest)
+	}
+
+	return !aws.TimeValue(version.LastModified).Before(aws.TimeValue(marker.LastModified))
+}
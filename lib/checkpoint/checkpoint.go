@@ -0,0 +1,129 @@
+// Package checkpoint saves the progress of long-running bulk commands to a local file, so that a run
+// that was interrupted can pick up where it stopped instead of listing everything again.
+package checkpoint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Version is the format version written to new checkpoint files. Load refuses files with any other
+// version, bump it whenever the meaning of a field changes.
+const Version = 1
+
+// Marker is the last entry a command finished with, listing resumes right after it. VersionID is
+// left empty by commands that work on keys rather than versions.
+type Marker struct {
+	Key       string `json:"key"`
+	VersionID string `json:"version_id,omitempty"`
+}
+
+// Checkpoint is the progress of one run of a command against a bucket.
+type Checkpoint struct {
+	Version int    `json:"version"`
+	Command string `json:"command"`
+	Bucket  string `json:"bucket"`
+
+	// Options describes whatever else decides what the command works on, e.g. prefixes and filters.
+	// Resuming with different options would skip entries the new options select, so Match refuses it.
+	Options string `json:"options"`
+
+	Marker   Marker           `json:"marker"`
+	Counters map[string]int64 `json:"counters"`
+	Updated  time.Time        `json:"updated"`
+}
+
+// New returns an empty checkpoint for a run of command against bucket with the given options.
+func New(command, bucket, options string) *Checkpoint {
+	return &Checkpoint{
+		Version:  Version,
+		Command:  command,
+		Bucket:   bucket,
+		Options:  options,
+		Counters: map[string]int64{},
+	}
+}
+
+// Load reads a checkpoint written by Save.
+func Load(path string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "package: checkpoint => func: Load => func call ioutil.ReadFile failed\n")
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, errors.Wrapf(err, "package: checkpoint => func: Load => %s is not a checkpoint file\n", path)
+	}
+
+	if cp.Version != Version {
+		return nil, errors.Errorf("package: checkpoint => func: Load => %s has format version %d, this build reads version %d\n", path, cp.Version, Version)
+	}
+
+	if cp.Counters == nil {
+		cp.Counters = map[string]int64{}
+	}
+
+	return cp, nil
+}
+
+// Match returns an error unless the checkpoint was saved by a run of command against bucket with
+// the same options.
+func (cp *Checkpoint) Match(command, bucket, options string) error {
+	if cp.Command != command || cp.Bucket != bucket {
+		return errors.Errorf("package: checkpoint => method: Match => checkpoint is for %s on s3://%s, not %s on s3://%s\n", cp.Command, cp.Bucket, command, bucket)
+	}
+
+	if cp.Options != options {
+		return errors.Errorf("package: checkpoint => method: Match => checkpoint was saved with options %q, not %q\n", cp.Options, options)
+	}
+
+	return nil
+}
+
+// Save writes the checkpoint to path. It writes a temporary file next to it first and renames it
+// into place, so a crash while saving leaves the previous checkpoint intact.
+func (cp *Checkpoint) Save(path string) error {
+	cp.Updated = time.Now().UTC()
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "package: checkpoint => method: Save => func call json.MarshalIndent failed\n")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "package: checkpoint => method: Save => func call ioutil.TempFile failed\n")
+	}
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		// nolint [:errcheck]
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "package: checkpoint => method: Save => writing the checkpoint failed\n")
+	}
+
+	return nil
+}
+
+// Remove deletes the checkpoint file at path once a run has finished, it's fine if there is none.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "package: checkpoint => func: Remove => func call os.Remove failed\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package checkpoint_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/checkpoint"
+)
+
+var _ = Describe("Checkpoint", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "checkpoint")
+		Expect(err).To(BeNil())
+		path = filepath.Join(dir, "run.checkpoint")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should load what was saved", func() {
+		cp := checkpoint.New("empty-bucket", "bucket", "prefix=a/")
+		cp.Marker = checkpoint.Marker{Key: "a/b", VersionID: "v1"}
+		cp.Counters["deleted"] = 42
+		Expect(cp.Save(path)).To(Succeed())
+
+		loaded, err := checkpoint.Load(path)
+		Expect(err).To(BeNil())
+		Expect(loaded.Version).To(Equal(checkpoint.Version))
+		Expect(loaded.Marker).To(Equal(cp.Marker))
+		Expect(loaded.Counters).To(Equal(map[string]int64{"deleted": 42}))
+		Expect(loaded.Updated.IsZero()).To(BeFalse())
+		Expect(loaded.Match("empty-bucket", "bucket", "prefix=a/")).To(Succeed())
+	})
+
+	It("should replace the previous checkpoint without leaving temporary files", func() {
+		cp := checkpoint.New("empty-bucket", "bucket", "")
+		Expect(cp.Save(path)).To(Succeed())
+
+		cp.Marker.Key = "later"
+		Expect(cp.Save(path)).To(Succeed())
+
+		loaded, err := checkpoint.Load(path)
+		Expect(err).To(BeNil())
+		Expect(loaded.Marker.Key).To(Equal("later"))
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).To(BeNil())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("should refuse a file with another format version", func() {
+		Expect(ioutil.WriteFile(path, []byte(`{"version": 99, "command": "empty-bucket"}`), 0600)).To(Succeed())
+
+		_, err := checkpoint.Load(path)
+		Expect(err).To(MatchError(ContainSubstring("format version 99")))
+	})
+
+	It("should refuse a file that is not a checkpoint", func() {
+		Expect(ioutil.WriteFile(path, []byte("key\tversion\n"), 0600)).To(Succeed())
+
+		_, err := checkpoint.Load(path)
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should not match another command, bucket, or options", func() {
+		cp := checkpoint.New("empty-bucket", "bucket", "prefix=a/")
+		Expect(cp.Match("abort-uploads", "bucket", "prefix=a/")).NotTo(Succeed())
+		Expect(cp.Match("empty-bucket", "other", "prefix=a/")).NotTo(Succeed())
+		Expect(cp.Match("empty-bucket", "bucket", "prefix=b/")).NotTo(Succeed())
+	})
+
+	It("should remove the file, or nothing when there is none", func() {
+		Expect(checkpoint.New("empty-bucket", "bucket", "").Save(path)).To(Succeed())
+		Expect(checkpoint.Remove(path)).To(Succeed())
+		Expect(checkpoint.Remove(path)).To(Succeed())
+
+		_, err := os.Stat(path)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,379 @@
+// Package inventory reads S3 Inventory reports, the daily or weekly listings S3 writes of a bucket,
+// as an alternative to listing a huge bucket live. A report is a manifest.json that describes the
+// schema and names the gzipped CSV data files holding the rows. Rows come out as s3svc.Versions,
+// so a Reader can stand in for a VersionIterator wherever the listing may be a day or so old.
+//
+// Reports in the ORC and Parquet formats are not supported.
+package inventory
+
+import (
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GetTerminus/s3helper/lib/aws/s3svc"
+	"github.com/pkg/errors"
+)
+
+// The fields of the inventory schema rows are read from. Bucket and Key are in every report, the
+// others only when the report was configured with them, and versions only for all versions.
+const (
+	FieldBucket         = "Bucket"
+	FieldKey            = "Key"
+	FieldVersionID      = "VersionId"
+	FieldIsLatest       = "IsLatest"
+	FieldIsDeleteMarker = "IsDeleteMarker"
+	FieldSize           = "Size"
+	FieldLastModified   = "LastModifiedDate"
+)
+
+// Manifest is the manifest.json of an inventory report.
+type Manifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	Version           string `json:"version"`
+	CreationTimestamp string `json:"creationTimestamp"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []File `json:"files"`
+}
+
+// File is one of the data files of an inventory report, with its key in the destination bucket.
+type File struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5Checksum string `json:"MD5checksum"`
+}
+
+// ParseManifest reads a manifest.json and checks it describes a CSV report with the fields every
+// report has.
+func ParseManifest(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, errors.Wrap(err, "package: inventory => func: ParseManifest => not an inventory manifest\n")
+	}
+
+	if m.FileFormat != "CSV" {
+		return nil, errors.Errorf("package: inventory => func: ParseManifest => the report is in the %q format, only CSV is supported\n", m.FileFormat)
+	}
+
+	if err := m.CheckSchema(FieldBucket, FieldKey); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Columns returns the fields of the schema in the order of the CSV columns.
+func (m *Manifest) Columns() []string {
+	columns := strings.Split(m.FileSchema, ",")
+	for i := range columns {
+		columns[i] = strings.TrimSpace(columns[i])
+	}
+
+	return columns
+}
+
+// CheckSchema returns an error naming the fields the schema doesn't have.
+func (m *Manifest) CheckSchema(fields ...string) error {
+	have := map[string]bool{}
+	for _, column := range m.Columns() {
+		have[column] = true
+	}
+
+	missing := []string{}
+	for _, field := range fields {
+		if !have[field] {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.Errorf("package: inventory => method: CheckSchema => the report has no %s, its schema is %q\n", strings.Join(missing, ", "), m.FileSchema)
+	}
+
+	return nil
+}
+
+// FilterFields returns the fields rows need for filtering them by age or by whether they are the
+// latest version to work. Without them every row would look last modified at the zero time, and be
+// the latest version, so the filters would pick the wrong rows rather than fail.
+func FilterFields(byAge, byLatest bool) []string {
+	fields := []string{}
+	if byAge {
+		fields = append(fields, FieldLastModified)
+	}
+	if byLatest {
+		fields = append(fields, FieldIsLatest)
+	}
+
+	return fields
+}
+
+// Versioned reports whether the report lists all versions and delete markers, rather than only the
+// current version of each key.
+func (m *Manifest) Versioned() bool {
+	return m.CheckSchema(FieldVersionID, FieldIsLatest, FieldIsDeleteMarker) == nil
+}
+
+// Created returns when S3 started the listing the report is made of.
+func (m *Manifest) Created() time.Time {
+	ms, err := strconv.ParseInt(m.CreationTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC()
+}
+
+// Bucket returns the name of the bucket the report is stored in, the manifest gives its ARN.
+func (m *Manifest) Bucket() string {
+	return strings.TrimPrefix(m.DestinationBucket, "arn:aws:s3:::")
+}
+
+// reportFolder matches the folders S3 writes each report of a configuration to, named after the time
+// it was started, like 2018-03-01T00-00Z/.
+var reportFolder = regexp.MustCompile(`(^|/)\d{4}-\d{2}-\d{2}T\d{2}-\d{2}Z/$`)
+
+// LatestManifest returns the key of the manifest of the newest report among the folders of an
+// inventory configuration, and false when none of them holds a report.
+func LatestManifest(folders []string) (string, bool) {
+	latest := ""
+	for _, folder := range folders {
+		// the names sort by time, but other folders like data/ and hive/ sit next to them
+		if reportFolder.MatchString(folder) && path.Base(folder) > path.Base(latest) {
+			latest = folder
+		}
+	}
+
+	if latest == "" {
+		return "", false
+	}
+
+	return latest + "manifest.json", true
+}
+
+// Opener opens a data file of a report by its key in the destination bucket.
+type Opener func(key string) (io.ReadCloser, error)
+
+// S3Opener opens data files from the destination bucket.
+func S3Opener(s3client *s3svc.Client, bucket string) Opener {
+	return func(key string) (io.ReadCloser, error) {
+		return s3client.GetObject(bucket, key)
+	}
+}
+
+// LocalOpener opens data files from a copy of a report on disk, looked up by name next to the
+// manifest, in a data directory next to it, or in the data directory one level up, where it is when
+// the destination prefix was copied as S3 lays it out.
+func LocalOpener(manifestPath string) Opener {
+	dir := filepath.Dir(manifestPath)
+
+	return func(key string) (io.ReadCloser, error) {
+		name := filepath.Base(key)
+		candidates := []string{
+			filepath.Join(dir, name),
+			filepath.Join(dir, "data", name),
+			filepath.Join(dir, "..", "data", name),
+		}
+
+		for _, candidate := range candidates {
+			f, err := os.Open(candidate)
+			if err == nil {
+				return f, nil
+			}
+
+			if !os.IsNotExist(err) {
+				return nil, errors.Wrap(err, "package: inventory => func: LocalOpener => func call os.Open failed\n")
+			}
+		}
+
+		return nil, errors.Errorf("package: inventory => func: LocalOpener => data file %s is not in %s\n", name, strings.Join(candidates[1:], " or "))
+	}
+}
+
+// Reader yields the rows of a report's data files as versions, file by file in the order of the
+// manifest. Rows are not sorted across files, so filters that need all versions of a key in a row,
+// like s3svc.FilterKeys, don't work on a Reader. A data file whose checksum does not match the
+// manifest stops the reader once it has been read.
+type Reader struct {
+	manifest *Manifest
+	open     Opener
+	columns  map[string]int
+
+	file    int
+	body    io.ReadCloser
+	digest  hash.Hash
+	rows    *csv.Reader
+	row     int
+	version *s3svc.Version
+	err     error
+}
+
+// NewReader returns a reader for the rows of the report described by the manifest, opening the
+// data files as it gets to them.
+func NewReader(m *Manifest, open Opener) *Reader {
+	columns := map[string]int{}
+	for i, column := range m.Columns() {
+		columns[column] = i
+	}
+
+	return &Reader{manifest: m, open: open, columns: columns}
+}
+
+// Next advances to the next row, returning false when there are no more or an error occurred.
+func (r *Reader) Next() bool {
+	for r.err == nil {
+		if r.rows == nil {
+			if r.file == len(r.manifest.Files) {
+				return false
+			}
+
+			r.err = r.openFile()
+			continue
+		}
+
+		record, err := r.rows.Read()
+		if err == io.EOF {
+			r.err = r.closeFile()
+			continue
+		}
+		r.row++
+
+		if err != nil {
+			r.err = errors.Wrapf(err, "package: inventory => method: Next => reading row %d of %s failed\n", r.row, r.manifest.Files[r.file].Key)
+			break
+		}
+
+		r.version, err = r.parse(record)
+		if err != nil {
+			r.err = errors.Wrapf(err, "package: inventory => method: Next => row %d of %s is invalid\n", r.row, r.manifest.Files[r.file].Key)
+			break
+		}
+
+		return true
+	}
+
+	r.discard()
+	return false
+}
+
+// Version returns the version the reader is positioned at by the last call to Next.
+func (r *Reader) Version() *s3svc.Version {
+	return r.version
+}
+
+// Err returns the error that stopped the reader, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// openFile opens the current data file, hashing it as it's read.
+func (r *Reader) openFile() error {
+	file := r.manifest.Files[r.file]
+
+	body, err := r.open(file.Key)
+	if err != nil {
+		return errors.Wrap(err, "package: inventory => method: openFile => func call open failed\n")
+	}
+
+	digest := md5.New()
+	unzip, err := gzip.NewReader(io.TeeReader(body, digest))
+	if err != nil {
+		// nolint [:errcheck]
+		body.Close()
+		return errors.Wrapf(err, "package: inventory => method: openFile => %s is not gzipped\n", file.Key)
+	}
+
+	r.body, r.digest, r.row = body, digest, 0
+	r.rows = csv.NewReader(unzip)
+	r.rows.FieldsPerRecord = len(r.columns)
+
+	return nil
+}
+
+// closeFile checks the checksum of the data file that was read to the end and moves on to the next.
+func (r *Reader) closeFile() error {
+	file := r.manifest.Files[r.file]
+
+	// the gzip reader may stop short of the end of the file, the checksum covers all of it
+	_, err := io.Copy(ioutil.Discard, io.TeeReader(r.body, r.digest))
+	r.discard()
+	r.file++
+
+	if err != nil {
+		return errors.Wrapf(err, "package: inventory => method: closeFile => reading %s failed\n", file.Key)
+	}
+
+	if sum := hex.EncodeToString(r.digest.Sum(nil)); file.MD5Checksum != "" && !strings.EqualFold(sum, file.MD5Checksum) {
+		return errors.Errorf("package: inventory => method: closeFile => %s has checksum %s, the manifest says %s\n", file.Key, sum, file.MD5Checksum)
+	}
+
+	return nil
+}
+
+// discard closes the current data file, if one is open.
+func (r *Reader) discard() {
+	if r.body == nil {
+		return
+	}
+
+	// nolint [:errcheck]
+	r.body.Close()
+	r.body, r.rows = nil, nil
+}
+
+// parse turns a row into a version. Keys are URL-encoded in inventory reports, and versions only
+// have the fields the schema gives them.
+func (r *Reader) parse(record []string) (*s3svc.Version, error) {
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+
+	key, err := url.QueryUnescape(field(FieldKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "package: inventory => method: parse => invalid key\n")
+	}
+
+	v := &s3svc.Version{
+		Key:            key,
+		VersionID:      field(FieldVersionID),
+		IsLatest:       field(FieldIsLatest) != "false",
+		IsDeleteMarker: field(FieldIsDeleteMarker) == "true",
+	}
+
+	// objects written before versioning was turned on have the null version, listed without an ID
+	if _, ok := r.columns[FieldVersionID]; ok && v.VersionID == "" {
+		v.VersionID = "null"
+	}
+
+	if size := field(FieldSize); size != "" {
+		if v.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return nil, errors.Wrap(err, "package: inventory => method: parse => invalid size\n")
+		}
+	}
+
+	if modified := field(FieldLastModified); modified != "" {
+		if v.LastModified, err = time.Parse(time.RFC3339Nano, modified); err != nil {
+			return nil, errors.Wrap(err, "package: inventory => method: parse => invalid last modified date\n")
+		}
+	}
+
+	return v, nil
+}
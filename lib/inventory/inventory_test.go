@@ -0,0 +1,278 @@
+package inventory_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/inventory"
+)
+
+const versionedSchema = "Bucket, Key, VersionId, IsLatest, IsDeleteMarker, Size, LastModifiedDate, ETag, StorageClass"
+
+// gzipped compresses rows of a data file.
+func gzipped(rows ...string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(strings.Join(rows, "\n") + "\n"))
+	Expect(err).To(BeNil())
+	Expect(zw.Close()).To(Succeed())
+
+	return buf.Bytes()
+}
+
+// checksum returns the MD5 checksum a manifest gives for data.
+func checksum(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// drain reads every row of a report as "key@version".
+func drain(reader *inventory.Reader) []string {
+	rows := []string{}
+	for reader.Next() {
+		rows = append(rows, reader.Version().Key+"@"+reader.Version().VersionID)
+	}
+
+	return rows
+}
+
+var _ = Describe("Inventory", func() {
+	var (
+		manifest *inventory.Manifest
+		files    map[string][]byte
+		opened   []string
+		open     inventory.Opener
+	)
+
+	BeforeEach(func() {
+		files = map[string][]byte{
+			"inv/src/all/data/one.csv.gz": gzipped(
+				`"src","a%2Fone","v2","true","false","10","2018-03-01T10:00:00.000Z","0123abcd","STANDARD"`,
+				`"src","a%2Fone","v1","false","false","20","2018-02-01T10:00:00.000Z","0123abcd","STANDARD"`,
+			),
+			"inv/src/all/data/two.csv.gz": gzipped(
+				`"src","with+space","","true","false","5","2018-01-01T10:00:00.000Z","0123abcd","STANDARD"`,
+				`"src","gone","dm","true","true","","2018-03-02T10:00:00.000Z","",""`,
+			),
+		}
+
+		manifest = &inventory.Manifest{
+			SourceBucket:      "src",
+			DestinationBucket: "arn:aws:s3:::inv-bucket",
+			CreationTimestamp: "1520000000000",
+			FileFormat:        "CSV",
+			FileSchema:        versionedSchema,
+			Files: []inventory.File{
+				{Key: "inv/src/all/data/one.csv.gz", MD5Checksum: checksum(files["inv/src/all/data/one.csv.gz"])},
+				{Key: "inv/src/all/data/two.csv.gz", MD5Checksum: checksum(files["inv/src/all/data/two.csv.gz"])},
+			},
+		}
+
+		opened = nil
+		open = func(key string) (io.ReadCloser, error) {
+			opened = append(opened, key)
+			data, ok := files[key]
+			if !ok {
+				return nil, errors.New("NoSuchKey")
+			}
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		}
+	})
+
+	Describe("ParseManifest", func() {
+		It("should read a CSV manifest", func() {
+			m, err := inventory.ParseManifest(strings.NewReader(`{
+				"sourceBucket": "src",
+				"destinationBucket": "arn:aws:s3:::inv-bucket",
+				"version": "2016-11-30",
+				"creationTimestamp": "1520000000000",
+				"fileFormat": "CSV",
+				"fileSchema": "Bucket, Key, Size",
+				"files": [{"key": "inv/src/all/data/one.csv.gz", "size": 42, "MD5checksum": "abc"}]
+			}`))
+			Expect(err).To(BeNil())
+			Expect(m.SourceBucket).To(Equal("src"))
+			Expect(m.Bucket()).To(Equal("inv-bucket"))
+			Expect(m.Created()).To(Equal(time.Date(2018, time.March, 2, 14, 13, 20, 0, time.UTC)))
+			Expect(m.Columns()).To(Equal([]string{"Bucket", "Key", "Size"}))
+			Expect(m.Files).To(Equal([]inventory.File{{Key: "inv/src/all/data/one.csv.gz", Size: 42, MD5Checksum: "abc"}}))
+			Expect(m.Versioned()).To(BeFalse())
+		})
+
+		It("should refuse other formats", func() {
+			_, err := inventory.ParseManifest(strings.NewReader(`{"fileFormat": "ORC", "fileSchema": "bucket:string,key:string"}`))
+			Expect(err).To(MatchError(ContainSubstring("only CSV is supported")))
+		})
+
+		It("should refuse a schema without keys", func() {
+			_, err := inventory.ParseManifest(strings.NewReader(`{"fileFormat": "CSV", "fileSchema": "Bucket, Size"}`))
+			Expect(err).To(MatchError(ContainSubstring("the report has no Key")))
+		})
+
+		It("should refuse anything else", func() {
+			_, err := inventory.ParseManifest(strings.NewReader(`<xml/>`))
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("CheckSchema", func() {
+		It("should name the missing fields", func() {
+			manifest.FileSchema = "Bucket, Key, Size"
+			Expect(manifest.CheckSchema(inventory.FieldKey, inventory.FieldSize)).To(Succeed())
+			Expect(manifest.CheckSchema(inventory.FieldVersionID, inventory.FieldSize, inventory.FieldIsLatest)).To(MatchError(ContainSubstring("no VersionId, IsLatest")))
+		})
+	})
+
+	Describe("FilterFields", func() {
+		It("should require the last modified date to filter by age", func() {
+			manifest.FileSchema = "Bucket, Key, VersionId, IsLatest, IsDeleteMarker, Size"
+			Expect(manifest.CheckSchema(inventory.FilterFields(false, true)...)).To(Succeed())
+			Expect(manifest.CheckSchema(inventory.FilterFields(true, false)...)).To(MatchError(ContainSubstring("no LastModifiedDate")))
+		})
+
+		It("should require whether a version is the latest to filter by it", func() {
+			manifest.FileSchema = "Bucket, Key, Size, LastModifiedDate"
+			Expect(manifest.CheckSchema(inventory.FilterFields(true, false)...)).To(Succeed())
+			Expect(manifest.CheckSchema(inventory.FilterFields(true, true)...)).To(MatchError(ContainSubstring("no IsLatest")))
+		})
+
+		It("should require nothing without filters", func() {
+			Expect(inventory.FilterFields(false, false)).To(BeEmpty())
+		})
+	})
+
+	Describe("LatestManifest", func() {
+		It("should pick the newest report folder", func() {
+			key, ok := inventory.LatestManifest([]string{
+				"inv/src/all/2018-03-02T00-00Z/",
+				"inv/src/all/2018-03-10T00-00Z/",
+				"inv/src/all/data/",
+				"inv/src/all/hive/",
+				"inv/src/all/2018-03-09T00-00Z/",
+			})
+			Expect(ok).To(BeTrue())
+			Expect(key).To(Equal("inv/src/all/2018-03-10T00-00Z/manifest.json"))
+		})
+
+		It("should find nothing without report folders", func() {
+			_, ok := inventory.LatestManifest([]string{"inv/src/all/data/", "inv/src/all/hive/"})
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Reader", func() {
+		It("should read the rows of every file in order", func() {
+			Expect(manifest.Versioned()).To(BeTrue())
+
+			reader := inventory.NewReader(manifest, open)
+			Expect(drain(reader)).To(Equal([]string{"a/one@v2", "a/one@v1", "with space@null", "gone@dm"}))
+			Expect(reader.Err()).To(BeNil())
+			Expect(opened).To(Equal([]string{"inv/src/all/data/one.csv.gz", "inv/src/all/data/two.csv.gz"}))
+		})
+
+		It("should fill in the fields of each version", func() {
+			reader := inventory.NewReader(manifest, open)
+
+			Expect(reader.Next()).To(BeTrue())
+			Expect(reader.Next()).To(BeTrue())
+			v := reader.Version()
+			Expect(v.IsLatest).To(BeFalse())
+			Expect(v.IsDeleteMarker).To(BeFalse())
+			Expect(v.Size).To(Equal(int64(20)))
+			Expect(v.LastModified).To(Equal(time.Date(2018, time.February, 1, 10, 0, 0, 0, time.UTC)))
+
+			Expect(reader.Next()).To(BeTrue())
+			Expect(reader.Next()).To(BeTrue())
+			v = reader.Version()
+			Expect(v.IsLatest).To(BeTrue())
+			Expect(v.IsDeleteMarker).To(BeTrue())
+			Expect(v.Size).To(Equal(int64(0)))
+		})
+
+		It("should read reports of current versions only", func() {
+			manifest.FileSchema = "Bucket, Key, Size, LastModifiedDate"
+			files["inv/src/all/data/one.csv.gz"] = gzipped(`"src","a","1","2018-01-01T10:00:00.000Z"`)
+			files["inv/src/all/data/two.csv.gz"] = gzipped(`"src","b","2","2018-01-01T10:00:00.000Z"`)
+			manifest.Files[0].MD5Checksum, manifest.Files[1].MD5Checksum = "", ""
+
+			reader := inventory.NewReader(manifest, open)
+			Expect(drain(reader)).To(Equal([]string{"a@", "b@"}))
+			Expect(reader.Err()).To(BeNil())
+		})
+
+		It("should stop at a file whose checksum does not match", func() {
+			manifest.Files[0].MD5Checksum = "0123456789abcdef0123456789abcdef"
+
+			reader := inventory.NewReader(manifest, open)
+			Expect(drain(reader)).To(Equal([]string{"a/one@v2", "a/one@v1"}))
+			Expect(reader.Err()).To(MatchError(ContainSubstring("the manifest says 0123456789abcdef0123456789abcdef")))
+			Expect(opened).To(HaveLen(1))
+		})
+
+		It("should stop at a file that cannot be opened", func() {
+			manifest.Files[1].Key = "inv/src/all/data/missing.csv.gz"
+
+			reader := inventory.NewReader(manifest, open)
+			Expect(drain(reader)).To(HaveLen(2))
+			Expect(reader.Err()).To(MatchError(ContainSubstring("NoSuchKey")))
+		})
+
+		It("should stop at a row that does not match the schema", func() {
+			files["inv/src/all/data/one.csv.gz"] = gzipped(`"src","a"`)
+			manifest.Files[0].MD5Checksum = ""
+
+			reader := inventory.NewReader(manifest, open)
+			Expect(drain(reader)).To(BeEmpty())
+			Expect(reader.Err()).To(MatchError(ContainSubstring("row 1 of inv/src/all/data/one.csv.gz")))
+		})
+
+		It("should stop at a file that is not gzipped", func() {
+			files["inv/src/all/data/one.csv.gz"] = []byte("plain text")
+
+			reader := inventory.NewReader(manifest, open)
+			Expect(drain(reader)).To(BeEmpty())
+			Expect(reader.Err()).To(MatchError(ContainSubstring("is not gzipped")))
+		})
+	})
+
+	Describe("LocalOpener", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "inventory")
+			Expect(err).To(BeNil())
+
+			Expect(os.MkdirAll(filepath.Join(dir, "2018-03-02T00-00Z"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "data"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "data", "one.csv.gz"), files["inv/src/all/data/one.csv.gz"], 0644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should find data files where S3 lays them out", func() {
+			open := inventory.LocalOpener(filepath.Join(dir, "2018-03-02T00-00Z", "manifest.json"))
+
+			f, err := open("inv/src/all/data/one.csv.gz")
+			Expect(err).To(BeNil())
+			Expect(f.Close()).To(Succeed())
+
+			_, err = open("inv/src/all/data/two.csv.gz")
+			Expect(err).To(MatchError(ContainSubstring("data file two.csv.gz is not in")))
+		})
+	})
+})
@@ -0,0 +1,254 @@
+// Package keylist reads lists of s3 object keys, optionally with a version ID each, as computed by
+// data-quality jobs or scripts, in one of three formats:
+//
+//	plain  one key per line, optionally followed by a tab and a version ID
+//	csv    key and version ID columns, either the first two or those named "key" and "version_id"
+//	       in a header row
+//	jsonl  one JSON object per line, with "key" and "version_id" or "versionId" fields
+//
+// Blank lines are skipped in every format. A key without a version ID stands for the key itself,
+// rather than one particular version of it.
+package keylist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Format is how a list of keys is written.
+type Format string
+
+// The formats Reader understands.
+const (
+	Plain Format = "plain"
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+// maxLine caps the length of a line in the plain and jsonl formats, well above S3's 1024 byte keys.
+const maxLine = 64 * 1024
+
+// FormatFor guesses the format of a list from the extension of its file name, anything that's not
+// .csv, .jsonl, .ndjson or .json is read as plain.
+func FormatFor(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return CSV
+	case ".jsonl", ".ndjson", ".json":
+		return JSONL
+	default:
+		return Plain
+	}
+}
+
+// Entry is a key from a list, with the version ID it was given with, if any.
+type Entry struct {
+	Key       string
+	VersionID string
+
+	// Line is where the entry was found, the line number in the plain and jsonl formats and the record
+	// number in the csv format, for error messages.
+	Line int
+}
+
+// Reader reads entries from a list one at a time.
+type Reader struct {
+	next  func() (*Entry, error)
+	entry *Entry
+	err   error
+}
+
+// NewReader returns a reader for a list of keys in the specified format.
+func NewReader(r io.Reader, format Format) (*Reader, error) {
+	switch format {
+	case Plain:
+		return &Reader{next: plainEntries(r)}, nil
+	case CSV:
+		return &Reader{next: csvEntries(r)}, nil
+	case JSONL:
+		return &Reader{next: jsonlEntries(r)}, nil
+	default:
+		return nil, errors.Errorf("package: keylist => func: NewReader => unknown format %q\n", format)
+	}
+}
+
+// Next advances to the next entry, returning false at the end of the list or when an error occurred.
+func (r *Reader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	r.entry, r.err = r.next()
+	if r.err == io.EOF {
+		r.err = nil
+	}
+
+	return r.entry != nil && r.err == nil
+}
+
+// Entry returns the entry the reader is positioned at by the last call to Next.
+func (r *Reader) Entry() *Entry {
+	return r.entry
+}
+
+// Err returns the error that stopped the reader, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// lines returns a function that reads the non-blank lines of r with their line numbers.
+func lines(r io.Reader) func() (string, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxLine)
+	n := 0
+
+	return func() (string, int, error) {
+		for scanner.Scan() {
+			n++
+
+			line := strings.TrimSuffix(scanner.Text(), "\r")
+			if strings.TrimSpace(line) != "" {
+				return line, n, nil
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return "", n + 1, errors.Wrapf(err, "package: keylist => func: lines => reading line %d failed\n", n+1)
+		}
+
+		return "", n, io.EOF
+	}
+}
+
+// plainEntries reads keys one per line, with a tab separating an optional version ID. Keys are
+// taken as they are, spaces included.
+func plainEntries(r io.Reader) func() (*Entry, error) {
+	next := lines(r)
+
+	return func() (*Entry, error) {
+		line, n, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &Entry{Key: line, Line: n}
+		if i := strings.IndexByte(line, '\t'); i >= 0 {
+			entry.Key, entry.VersionID = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		return entry, checkEntry(entry)
+	}
+}
+
+// csvEntries reads keys and version IDs from CSV records. A first record with a field named "key" is
+// a header that names the columns, otherwise the key comes first and the version ID second.
+func csvEntries(r io.Reader) func() (*Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	keyCol, versionCol := 0, 1
+	n := 0
+
+	return func() (*Entry, error) {
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return nil, err
+			}
+			n++
+
+			if err != nil {
+				return nil, errors.Wrapf(err, "package: keylist => func: csvEntries => reading record %d failed\n", n)
+			}
+
+			if n == 1 {
+				if col, ok := headerColumns(record); ok {
+					keyCol, versionCol = col["key"], -1
+					if i, ok := col["version_id"]; ok {
+						versionCol = i
+					}
+					continue
+				}
+			}
+
+			if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+				continue
+			}
+
+			if keyCol >= len(record) {
+				return nil, errors.Errorf("package: keylist => func: csvEntries => record %d has no key column\n", n)
+			}
+
+			entry := &Entry{Key: record[keyCol], Line: n}
+			if versionCol >= 0 && versionCol < len(record) {
+				entry.VersionID = strings.TrimSpace(record[versionCol])
+			}
+
+			return entry, checkEntry(entry)
+		}
+	}
+}
+
+// headerColumns returns where the key and version_id columns of a header record are, with versionid
+// standing for version_id, and whether the record is a header, which has a key column.
+func headerColumns(record []string) (map[string]int, bool) {
+	col := map[string]int{}
+	for i, name := range record {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "key":
+			col["key"] = i
+		case "version_id", "versionid":
+			col["version_id"] = i
+		}
+	}
+
+	_, ok := col["key"]
+	return col, ok
+}
+
+// jsonlEntries reads keys and version IDs from a JSON object per line.
+func jsonlEntries(r io.Reader) func() (*Entry, error) {
+	next := lines(r)
+
+	return func() (*Entry, error) {
+		line, n, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		var object struct {
+			Key       string `json:"key"`
+			VersionID string `json:"version_id"`
+
+			// the spelling of the AWS CLI and SDKs, matched without regard to case
+			VersionIDCamel string `json:"versionId"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			return nil, errors.Wrapf(err, "package: keylist => func: jsonlEntries => line %d is not a JSON object\n", n)
+		}
+
+		entry := &Entry{Key: object.Key, VersionID: object.VersionID, Line: n}
+		if entry.VersionID == "" {
+			entry.VersionID = object.VersionIDCamel
+		}
+
+		return entry, checkEntry(entry)
+	}
+}
+
+// checkEntry returns an error for an entry without a key.
+func checkEntry(entry *Entry) error {
+	if entry.Key == "" {
+		return errors.Errorf("package: keylist => func: checkEntry => line %d has no key\n", entry.Line)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package keylist_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestKeylist(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Keylist Suite")
+}
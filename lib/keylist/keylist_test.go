@@ -0,0 +1,105 @@
+package keylist_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/keylist"
+)
+
+// readAll reads every entry of a list as "key@version", and the error that stopped the reader.
+func readAll(list string, format keylist.Format) ([]string, error) {
+	reader, err := keylist.NewReader(strings.NewReader(list), format)
+	Expect(err).To(BeNil())
+
+	entries := []string{}
+	for reader.Next() {
+		entries = append(entries, reader.Entry().Key+"@"+reader.Entry().VersionID)
+	}
+
+	return entries, reader.Err()
+}
+
+var _ = Describe("Keylist", func() {
+	Describe("FormatFor", func() {
+		It("should go by the file extension", func() {
+			Expect(keylist.FormatFor("bad-keys.CSV")).To(Equal(keylist.CSV))
+			Expect(keylist.FormatFor("bad-keys.jsonl")).To(Equal(keylist.JSONL))
+			Expect(keylist.FormatFor("bad-keys.ndjson")).To(Equal(keylist.JSONL))
+			Expect(keylist.FormatFor("bad-keys.txt")).To(Equal(keylist.Plain))
+			Expect(keylist.FormatFor("-")).To(Equal(keylist.Plain))
+		})
+	})
+
+	Describe("NewReader", func() {
+		It("should refuse unknown formats", func() {
+			_, err := keylist.NewReader(strings.NewReader(""), "xml")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("plain", func() {
+		It("should read a key per line with optional version IDs", func() {
+			entries, err := readAll("a/one\r\n\nb/with space\tv1\n  \nc\n", keylist.Plain)
+			Expect(err).To(BeNil())
+			Expect(entries).To(Equal([]string{"a/one@", "b/with space@v1", "c@"}))
+		})
+
+		It("should report an empty key with its line", func() {
+			entries, err := readAll("a\n\tv1\nb\n", keylist.Plain)
+			Expect(entries).To(Equal([]string{"a@"}))
+			Expect(err).To(MatchError(ContainSubstring("line 2 has no key")))
+		})
+	})
+
+	Describe("csv", func() {
+		It("should read the key and version ID columns", func() {
+			entries, err := readAll("a/one,v1\n\"b,quoted\"\nc,\n", keylist.CSV)
+			Expect(err).To(BeNil())
+			Expect(entries).To(Equal([]string{"a/one@v1", "b,quoted@", "c@"}))
+		})
+
+		It("should find the columns by name in a header", func() {
+			entries, err := readAll("key,size,version_id\na,10,v1\nb,20,v2\n", keylist.CSV)
+			Expect(err).To(BeNil())
+			Expect(entries).To(Equal([]string{"a@v1", "b@v2"}))
+		})
+
+		It("should find the key column anywhere in a header", func() {
+			entries, err := readAll("version_id,key\nv1,a\nv2,b\n", keylist.CSV)
+			Expect(err).To(BeNil())
+			Expect(entries).To(Equal([]string{"a@v1", "b@v2"}))
+
+			entries, err = readAll("size,VersionId,Key\n10,v1,a\n", keylist.CSV)
+			Expect(err).To(BeNil())
+			Expect(entries).To(Equal([]string{"a@v1"}))
+		})
+
+		It("should read only keys with a header without versions", func() {
+			entries, err := readAll("Key,Size\na,10\n", keylist.CSV)
+			Expect(err).To(BeNil())
+			Expect(entries).To(Equal([]string{"a@"}))
+		})
+
+		It("should report broken records", func() {
+			_, err := readAll("a,v1\n\"b,v2\n", keylist.CSV)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("jsonl", func() {
+		It("should read keys and version IDs in either spelling", func() {
+			entries, err := readAll("{\"key\": \"a\", \"version_id\": \"v1\"}\n\n{\"Key\": \"b\", \"VersionId\": \"v2\"}\n{\"key\": \"c\"}\n", keylist.JSONL)
+			Expect(err).To(BeNil())
+			Expect(entries).To(Equal([]string{"a@v1", "b@v2", "c@"}))
+		})
+
+		It("should report lines that are not JSON objects", func() {
+			entries, err := readAll("{\"key\": \"a\"}\na\n", keylist.JSONL)
+			Expect(entries).To(Equal([]string{"a@"}))
+			Expect(err).To(MatchError(ContainSubstring("line 2 is not a JSON object")))
+		})
+	})
+})
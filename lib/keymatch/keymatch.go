@@ -0,0 +1,209 @@
+// Package keymatch selects s3 object keys with glob or regular expression patterns.
+//
+// Globs follow the rules of a .gitignore file, adapted to keys:
+//
+//	"*"      matches anything except "/"
+//	"?"      matches a single character except "/"
+//	"[a-z]"  matches a character class, "[!a-z]" negates it
+//	"**"     matches anything, including "/"; "**/" may also match nothing
+//	"\"      makes the next character literal, e.g. "\*" or "\?"
+//
+// A glob without a "/" is matched against the last segment of the key, so "*.tmp" selects
+// "a.tmp" as well as "logs/2018/b.tmp". A trailing "/" of the key is ignored for this, so "logs"
+// selects the folder placeholder "archive/logs/". A glob with a "/" must match the whole key,
+// e.g. "_spark_metadata/**" only selects keys under the top-level "_spark_metadata/".
+//
+// Patterns starting with "re:" are regular expressions in Go's RE2 syntax, matched anywhere in
+// the key unless anchored with ^ and $. Escape the first character, as in "\re:", for a glob
+// that starts with "re:".
+package keymatch
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// regexPrefix marks a pattern as a regular expression instead of a glob.
+const regexPrefix = "re:"
+
+// Pattern matches object keys against a single glob or regular expression.
+type Pattern struct {
+	raw      string
+	re       *regexp.Regexp
+	lastPart bool
+}
+
+// Compile parses a glob or, when prefixed with "re:", a regular expression.
+func Compile(pattern string) (*Pattern, error) {
+	if strings.HasPrefix(pattern, regexPrefix) {
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, regexPrefix))
+		if err != nil {
+			return nil, errors.Wrapf(err, "package: keymatch => func: Compile => invalid regular expression %q\n", pattern)
+		}
+
+		return &Pattern{raw: pattern, re: re}, nil
+	}
+
+	expr, hasSlash, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "package: keymatch => func: Compile => invalid glob %q\n", pattern)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "package: keymatch => func: Compile => invalid glob %q\n", pattern)
+	}
+
+	return &Pattern{raw: pattern, re: re, lastPart: !hasSlash}, nil
+}
+
+// Match reports whether the key matches the pattern.
+func (p *Pattern) Match(key string) bool {
+	if p.lastPart {
+		key = strings.TrimSuffix(key, "/")
+		key = key[strings.LastIndex(key, "/")+1:]
+	}
+
+	return p.re.MatchString(key)
+}
+
+// String returns the pattern as it was given.
+func (p *Pattern) String() string {
+	return p.raw
+}
+
+// globToRegexp translates a glob into an anchored regular expression, and reports whether the glob
+// contains an unescaped "/".
+func globToRegexp(glob string) (string, bool, error) {
+	var (
+		expr     strings.Builder
+		hasSlash bool
+	)
+
+	expr.WriteString("^")
+
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\\':
+			if i+1 == len(runes) {
+				return "", false, errors.New("trailing backslash")
+			}
+
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+
+				// "**/" may also stand for no directories at all
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					hasSlash = true
+					expr.WriteString("(?:.*/)?")
+					continue
+				}
+
+				expr.WriteString(".*")
+				continue
+			}
+
+			expr.WriteString("[^/]*")
+
+		case '?':
+			expr.WriteString("[^/]")
+
+		case '[':
+			end := i + 1
+			if end < len(runes) && runes[end] == '!' {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return "", false, errors.New("unterminated character class")
+			}
+
+			class := strings.Replace(string(runes[i+1:end]), `\`, `\\`, -1)
+
+			// like "*" and "?", a negated class never matches "/"
+			if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+				class = "^/" + class[1:]
+			}
+
+			expr.WriteString("[" + class + "]")
+			i = end
+
+		case '/':
+			hasSlash = true
+			expr.WriteString("/")
+
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	expr.WriteString("$")
+
+	return expr.String(), hasSlash, nil
+}
+
+// Matcher selects keys that match at least one include pattern, or any key when there are none,
+// and none of the exclude patterns.
+type Matcher struct {
+	includes []*Pattern
+	excludes []*Pattern
+}
+
+// New compiles the include and exclude patterns into a Matcher.
+func New(includes, excludes []string) (*Matcher, error) {
+	m := &Matcher{}
+
+	for _, include := range includes {
+		p, err := Compile(include)
+		if err != nil {
+			return nil, errors.Wrap(err, "package: keymatch => func: New => func call keymatch.Compile failed\n")
+		}
+
+		m.includes = append(m.includes, p)
+	}
+
+	for _, exclude := range excludes {
+		p, err := Compile(exclude)
+		if err != nil {
+			return nil, errors.Wrap(err, "package: keymatch => func: New => func call keymatch.Compile failed\n")
+		}
+
+		m.excludes = append(m.excludes, p)
+	}
+
+	return m, nil
+}
+
+// Match reports whether the key is selected by the matcher.
+func (m *Matcher) Match(key string) bool {
+	for _, p := range m.excludes {
+		if p.Match(key) {
+			return false
+		}
+	}
+
+	if len(m.includes) == 0 {
+		return true
+	}
+
+	for _, p := range m.includes {
+		if p.Match(key) {
+			return true
+		}
+	}
+
+	return false
+}
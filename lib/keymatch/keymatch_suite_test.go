@@ -0,0 +1,13 @@
+package keymatch_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestKeymatch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Keymatch Suite")
+}
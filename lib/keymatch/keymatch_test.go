@@ -0,0 +1,123 @@
+package keymatch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/keymatch"
+)
+
+var _ = Describe("Keymatch", func() {
+	Describe("Pattern", func() {
+		cases := []struct {
+			name     string
+			pattern  string
+			key      string
+			expected bool
+		}{
+			// globs without a slash match the last segment of the key
+			{"extension at the root", "*.tmp", "a.tmp", true},
+			{"extension in a folder", "*.tmp", "logs/2018/b.tmp", true},
+			{"extension only as a suffix", "*.tmp", "a.tmp.gz", false},
+			{"dot is literal", "*.tmp", "atmp", false},
+			{"extension in a folder name", "*.tmp", "x.tmp/data", false},
+			{"folder placeholder", "logs", "archive/logs/", true},
+			{"question mark is one character", "part-?", "out/part-1", true},
+			{"question mark is exactly one character", "part-?", "out/part-12", false},
+
+			// globs with a slash match the whole key
+			{"double star below a top-level folder", "_spark_metadata/**", "_spark_metadata/0", true},
+			{"double star crosses folders", "_spark_metadata/**", "_spark_metadata/a/b/c", true},
+			{"double star matches the folder placeholder", "_spark_metadata/**", "_spark_metadata/", true},
+			{"anchored to the root", "_spark_metadata/**", "table/_spark_metadata/0", false},
+			{"leading double star matches any depth", "**/_spark_metadata/**", "table/_spark_metadata/0", true},
+			{"leading double star matches no folders", "**/_spark_metadata/**", "_spark_metadata/0", true},
+			{"leading double star needs a whole segment", "**/_spark_metadata/**", "table_spark_metadata/0", false},
+			{"single star stays in a segment", "logs/*", "logs/a/b", false},
+			{"single star within a segment", "logs/*", "logs/a", true},
+			{"trailing slash is part of the key", "logs/*/", "logs/2018/", true},
+			{"missing trailing slash", "logs/*/", "logs/2018", false},
+
+			// literal and special characters
+			{"escaped star is literal", `weird\*name.txt`, "weird*name.txt", true},
+			{"escaped star is not a wildcard", `weird\*name.txt`, "weirdXname.txt", false},
+			{"escaped question mark", `what\?`, "what?", true},
+			{"regexp characters in a glob are literal", "a+b(1).tmp", "dir/a+b(1).tmp", true},
+			{"spaces", "my file *.txt", "docs/my file 2.txt", true},
+			{"character class", "part-[0-4]", "part-3", true},
+			{"character class excludes", "part-[0-4]", "part-7", false},
+			{"negated character class", "part-[!0-4]", "part-7", true},
+			{"negated character class never matches a slash", "a[!x]b/*", "a/b/c", false},
+
+			// unicode
+			{"unicode literal", "été.csv", "données/été.csv", true},
+			{"question mark is one unicode character", "?.txt", "日本/ü.txt", true},
+			{"question mark is not one byte", "??.txt", "ü.txt", false},
+			{"unicode folder", "日本/**", "日本/ファイル.txt", true},
+
+			// regular expressions
+			{"regexp matches anywhere", `re:\.tmp$`, "a/b.tmp", true},
+			{"regexp can cross folders", "re:^logs/.*/debug", "logs/2018/01/debug.log", true},
+			{"regexp anchored", "re:^logs/", "old/logs/a", false},
+			{"escaped prefix is a glob", `\re:*`, "re:abc", true},
+		}
+
+		for _, c := range cases {
+			c := c
+
+			It("should handle "+c.name, func() {
+				p, err := keymatch.Compile(c.pattern)
+				Expect(err).To(BeNil())
+				Expect(p.Match(c.key)).To(Equal(c.expected), "%s against %s", c.pattern, c.key)
+			})
+		}
+
+		It("should reject invalid patterns", func() {
+			for _, pattern := range []string{"re:(", "part-[0-9", `trailing\`} {
+				_, err := keymatch.Compile(pattern)
+				Expect(err).NotTo(BeNil(), pattern)
+			}
+		})
+
+		It("should remember the original pattern", func() {
+			p, err := keymatch.Compile("**/*.tmp")
+			Expect(err).To(BeNil())
+			Expect(p.String()).To(Equal("**/*.tmp"))
+		})
+	})
+
+	Describe("Matcher", func() {
+		It("should match everything without patterns", func() {
+			m, err := keymatch.New(nil, nil)
+			Expect(err).To(BeNil())
+			Expect(m.Match("anything/at/all")).To(BeTrue())
+		})
+
+		It("should match keys that match any include", func() {
+			m, err := keymatch.New([]string{"*.tmp", "_spark_metadata/**"}, nil)
+			Expect(err).To(BeNil())
+			Expect(m.Match("a/b.tmp")).To(BeTrue())
+			Expect(m.Match("_spark_metadata/1")).To(BeTrue())
+			Expect(m.Match("a/b.csv")).To(BeFalse())
+		})
+
+		It("should let excludes win over includes", func() {
+			m, err := keymatch.New([]string{"*.tmp"}, []string{"keep/**"})
+			Expect(err).To(BeNil())
+			Expect(m.Match("drop/a.tmp")).To(BeTrue())
+			Expect(m.Match("keep/a.tmp")).To(BeFalse())
+		})
+
+		It("should match everything but the excludes without includes", func() {
+			m, err := keymatch.New(nil, []string{"re:^important/"})
+			Expect(err).To(BeNil())
+			Expect(m.Match("scratch/a")).To(BeTrue())
+			Expect(m.Match("important/a")).To(BeFalse())
+		})
+
+		It("should report invalid patterns", func() {
+			_, err := keymatch.New([]string{"*.tmp"}, []string{"re:["})
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
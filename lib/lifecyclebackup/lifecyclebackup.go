@@ -0,0 +1,81 @@
+// Package lifecyclebackup saves the lifecycle configuration of a bucket to a local file before it is
+// replaced, so that it can be put back once the rules that replaced it have done their work.
+package lifecyclebackup
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// Version is the format version written to new backup files. Load refuses files with any other
+// version.
+const Version = 1
+
+// Backup is the lifecycle configuration a bucket had, no rules means it had none.
+type Backup struct {
+	Version int                 `json:"version"`
+	Bucket  string              `json:"bucket"`
+	Saved   time.Time           `json:"saved"`
+	Rules   []*s3.LifecycleRule `json:"rules"`
+}
+
+// Save writes the rules of bucket to a new backup file at path. It never overwrites an existing one,
+// which may be the only copy of the configuration a bucket had before an earlier run.
+func Save(path, bucket string, rules []*s3.LifecycleRule) error {
+	backup := &Backup{Version: Version, Bucket: bucket, Saved: time.Now().UTC(), Rules: rules}
+
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "package: lifecyclebackup => func: Save => func call json.MarshalIndent failed\n")
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return errors.Errorf("package: lifecyclebackup => func: Save => %s already exists\n", path)
+		}
+
+		return errors.Wrap(err, "package: lifecyclebackup => func: Save => func call os.OpenFile failed\n")
+	}
+
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		// nolint [:errcheck]
+		os.Remove(path)
+		return errors.Wrap(err, "package: lifecyclebackup => func: Save => writing the backup failed\n")
+	}
+
+	return nil
+}
+
+// Load reads a backup of bucket's lifecycle configuration written by Save.
+func Load(path, bucket string) (*Backup, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "package: lifecyclebackup => func: Load => func call ioutil.ReadFile failed\n")
+	}
+
+	backup := &Backup{}
+	if err := json.Unmarshal(data, backup); err != nil {
+		return nil, errors.Wrapf(err, "package: lifecyclebackup => func: Load => %s is not a lifecycle backup\n", path)
+	}
+
+	if backup.Version != Version {
+		return nil, errors.Errorf("package: lifecyclebackup => func: Load => %s has format version %d, this build reads version %d\n", path, backup.Version, Version)
+	}
+
+	if backup.Bucket != bucket {
+		return nil, errors.Errorf("package: lifecyclebackup => func: Load => %s is the lifecycle configuration of s3://%s, not s3://%s\n", path, backup.Bucket, bucket)
+	}
+
+	return backup, nil
+}
@@ -0,0 +1,13 @@
+package lifecyclebackup_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLifecyclebackup(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lifecyclebackup Suite")
+}
@@ -0,0 +1,89 @@
+package lifecyclebackup_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/GetTerminus/s3helper/lib/lifecyclebackup"
+)
+
+var _ = Describe("Lifecyclebackup", func() {
+	var (
+		dir   string
+		path  string
+		rules []*s3.LifecycleRule
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "lifecyclebackup")
+		Expect(err).To(BeNil())
+		path = filepath.Join(dir, "bucket.lifecycle-backup.json")
+
+		rules = []*s3.LifecycleRule{{
+			ID:         aws.String("archive"),
+			Filter:     &s3.LifecycleRuleFilter{Prefix: aws.String("data/")},
+			Status:     aws.String("Enabled"),
+			Expiration: &s3.LifecycleExpiration{Days: aws.Int64(30)},
+		}}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should load what was saved", func() {
+		Expect(lifecyclebackup.Save(path, "bucket", rules)).To(Succeed())
+
+		backup, err := lifecyclebackup.Load(path, "bucket")
+		Expect(err).To(BeNil())
+		Expect(backup.Version).To(Equal(lifecyclebackup.Version))
+		Expect(backup.Rules).To(Equal(rules))
+		Expect(backup.Saved.IsZero()).To(BeFalse())
+	})
+
+	It("should save a bucket without rules", func() {
+		Expect(lifecyclebackup.Save(path, "bucket", nil)).To(Succeed())
+
+		backup, err := lifecyclebackup.Load(path, "bucket")
+		Expect(err).To(BeNil())
+		Expect(backup.Rules).To(BeEmpty())
+	})
+
+	It("should never overwrite a backup", func() {
+		Expect(lifecyclebackup.Save(path, "bucket", rules)).To(Succeed())
+		Expect(lifecyclebackup.Save(path, "bucket", nil)).To(MatchError(ContainSubstring("already exists")))
+
+		backup, err := lifecyclebackup.Load(path, "bucket")
+		Expect(err).To(BeNil())
+		Expect(backup.Rules).To(Equal(rules))
+	})
+
+	It("should refuse a backup of another bucket", func() {
+		Expect(lifecyclebackup.Save(path, "bucket", rules)).To(Succeed())
+
+		_, err := lifecyclebackup.Load(path, "other")
+		Expect(err).To(MatchError(ContainSubstring("of s3://bucket, not s3://other")))
+	})
+
+	It("should refuse other format versions", func() {
+		Expect(ioutil.WriteFile(path, []byte(`{"version": 99, "bucket": "bucket"}`), 0644)).To(Succeed())
+
+		_, err := lifecyclebackup.Load(path, "bucket")
+		Expect(err).To(MatchError(ContainSubstring("format version 99")))
+	})
+
+	It("should refuse files that are not backups", func() {
+		Expect(ioutil.WriteFile(path, []byte("not json"), 0644)).To(Succeed())
+
+		_, err := lifecyclebackup.Load(path, "bucket")
+		Expect(err).To(MatchError(ContainSubstring("is not a lifecycle backup")))
+	})
+})
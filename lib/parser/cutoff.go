@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Cutoff is a point in time given on the command line, either as an age relative to now, like "90d",
+// "2w" or "36h", or as an RFC3339 timestamp. The zero value means no cutoff was given.
+type Cutoff struct {
+	time.Time
+}
+
+// UnmarshalFlag implements the go-flags Unmarshaler interface.
+func (c *Cutoff) UnmarshalFlag(value string) error {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		c.Time = t
+		return nil
+	}
+
+	age, err := parseAge(value)
+	if err != nil {
+		return errors.Errorf("invalid cutoff %q, expected an age like 90d, 2w, 36h or an RFC3339 timestamp", value)
+	}
+
+	c.Time = time.Now().Add(-age)
+	return nil
+}
+
+// parseAge understands the units of time.ParseDuration plus whole days ("d") and weeks ("w").
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if !strings.HasSuffix(value, suffix) {
+			continue
+		}
+
+		n, err := strconv.ParseUint(strings.TrimSuffix(value, suffix), 10, 16)
+		if err != nil {
+			return 0, err
+		}
+
+		return time.Duration(n) * unit, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if age < 0 {
+		return 0, errors.New("age must not be negative")
+	}
+
+	return age, nil
+}
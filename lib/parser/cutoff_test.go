@@ -0,0 +1,82 @@
+package parser_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/parser"
+)
+
+var _ = Describe("Cutoff", func() {
+	var (
+		cutoff    parser.Cutoff
+		actualErr error
+		value     string
+	)
+
+	JustBeforeEach(func() {
+		cutoff = parser.Cutoff{}
+		actualErr = cutoff.UnmarshalFlag(value)
+	})
+
+	Context("when given an RFC3339 timestamp", func() {
+		BeforeEach(func() {
+			value = "2018-01-02T03:04:05Z"
+		})
+
+		It("should use it as is", func() {
+			Expect(actualErr).To(BeNil())
+			Expect(cutoff.Time).To(Equal(time.Date(2018, time.January, 2, 3, 4, 5, 0, time.UTC)))
+		})
+	})
+
+	Context("when given an age in days", func() {
+		BeforeEach(func() {
+			value = "90d"
+		})
+
+		It("should count back from now", func() {
+			Expect(actualErr).To(BeNil())
+			Expect(cutoff.Time).To(BeTemporally("~", time.Now().Add(-90*24*time.Hour), time.Second))
+		})
+	})
+
+	Context("when given an age in weeks", func() {
+		BeforeEach(func() {
+			value = "2w"
+		})
+
+		It("should count back from now", func() {
+			Expect(actualErr).To(BeNil())
+			Expect(cutoff.Time).To(BeTemporally("~", time.Now().Add(-14*24*time.Hour), time.Second))
+		})
+	})
+
+	Context("when given a go duration", func() {
+		BeforeEach(func() {
+			value = "1h30m"
+		})
+
+		It("should count back from now", func() {
+			Expect(actualErr).To(BeNil())
+			Expect(cutoff.Time).To(BeTemporally("~", time.Now().Add(-90*time.Minute), time.Second))
+		})
+	})
+
+	for _, invalid := range []string{"", "d", "1.5d", "-3d", "-1h", "tomorrow", "2018-01-02"} {
+		invalid := invalid
+
+		Context("when given "+invalid, func() {
+			BeforeEach(func() {
+				value = invalid
+			})
+
+			It("should return an error", func() {
+				Expect(actualErr).NotTo(BeNil())
+				Expect(cutoff.IsZero()).To(BeTrue())
+			})
+		})
+	}
+})
@@ -1,14 +1,21 @@
 package parser
 
 import (
+	"time"
+
 	"github.com/jessevdk/go-flags"
 )
 
 // GlobalOpts represents the options that can be passed to all (sub)commands.
 var GlobalOpts struct {
-	Region  string `short:"r" long:"region" description:"The region the s3 bucket resides in" required:"false" default:"us-east-1"`
-	Profile string `short:"p" long:"profile" description:"AWS Credential profile" required:"false"`
-	Verbose bool   `short:"v" long:"verbose" description:"Verbose output" required:"false"`
+	Region          string        `short:"r" long:"region" description:"The region the s3 bucket resides in" required:"false" default:"us-east-1"`
+	Profile         string        `short:"p" long:"profile" description:"AWS Credential profile" required:"false"`
+	RoleARNs        []string      `long:"role-arn" value-name:"arn" description:"assume this role, repeat to chain roles, each assumed with the credentials of the one before" required:"false"`
+	ExternalID      string        `long:"external-id" value-name:"id" description:"external id to assume the last --role-arn with" required:"false"`
+	RoleSessionName string        `long:"role-session-name" value-name:"name" description:"session name to assume roles with, shows up in CloudTrail" required:"false" default:"s3helper"`
+	Duration        time.Duration `long:"duration" value-name:"duration" description:"how long assumed role credentials last, they are renewed before they run out during long runs; AWS allows at most 1h when chaining roles" required:"false" default:"1h"`
+	RoleMFASerial   string        `long:"role-mfa-serial" value-name:"arn" description:"MFA device to assume the first --role-arn with, asks for a code on the terminal each time" required:"false"`
+	Verbose         bool          `short:"v" long:"verbose" description:"Verbose output" required:"false"`
 }
 
 // OptParser is a pointer to the instantiated go-flag Parser object.
@@ -0,0 +1,122 @@
+// Package progress shows how far a long-running bulk command has got while it works.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Counts is a snapshot of the numbers a Display shows.
+type Counts struct {
+	Listed  int64
+	Deleted int64
+	Failed  int64
+}
+
+// Display shows counts on w every interval while it runs. On a terminal it redraws a single status
+// line in place, otherwise it prints one log line per interval so redirected output stays readable.
+type Display struct {
+	mu       sync.Mutex
+	w        io.Writer
+	tty      bool
+	interval time.Duration
+	read     func() Counts
+
+	start time.Time
+	drawn bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a display that writes to w, redrawing in place when tty is set.
+func New(w io.Writer, tty bool, interval time.Duration) *Display {
+	return &Display{w: w, tty: tty, interval: interval}
+}
+
+// Start shows the counts returned by read every interval until Stop is called.
+func (d *Display) Start(read func() Counts) {
+	d.read = read
+	d.start = time.Now()
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				d.show()
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the display and shows the final counts on a line of their own.
+func (d *Display) Stop() {
+	close(d.stop)
+	<-d.done
+
+	d.show()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.tty {
+		// nolint [:gas]
+		fmt.Fprintln(d.w)
+		d.drawn = false
+	}
+}
+
+// Lock keeps the display from drawing until Unlock, and clears the status line from a terminal, so
+// that something else can write there, like a prompt. Display implements sync.Locker.
+func (d *Display) Lock() {
+	d.mu.Lock()
+
+	if d.drawn {
+		// nolint [:gas]
+		fmt.Fprint(d.w, "\r\x1b[K")
+		d.drawn = false
+	}
+}
+
+// Unlock lets the display draw again from its next interval.
+func (d *Display) Unlock() {
+	d.mu.Unlock()
+}
+
+func (d *Display) show() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	line := Line(d.read(), time.Since(d.start))
+
+	if d.tty {
+		// nolint [:gas]
+		fmt.Fprint(d.w, "\r"+line+"\x1b[K")
+		d.drawn = true
+		return
+	}
+
+	// nolint [:gas]
+	fmt.Fprintln(d.w, time.Now().UTC().Format(time.RFC3339)+" "+line)
+}
+
+// Line formats counts after running for elapsed, with the average number deleted per second.
+func Line(c Counts, elapsed time.Duration) string {
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(c.Deleted) / elapsed.Seconds()
+	}
+
+	return fmt.Sprintf("listed %d, deleted %d, failed %d, %.0f/s, elapsed %s", c.Listed, c.Deleted, c.Failed, rate, elapsed.Truncate(time.Second))
+}
@@ -0,0 +1,13 @@
+package progress_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestProgress(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Progress Suite")
+}
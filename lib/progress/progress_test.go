@@ -0,0 +1,104 @@
+package progress_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/progress"
+)
+
+// syncBuffer is a bytes.Buffer that is safe to write from the display goroutine and read from the test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+var _ = Describe("Display", func() {
+	var (
+		out    *syncBuffer
+		counts progress.Counts
+		mu     sync.Mutex
+
+		read = func() progress.Counts {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return counts
+		}
+	)
+
+	BeforeEach(func() {
+		out = &syncBuffer{}
+		counts = progress.Counts{Listed: 10, Deleted: 8, Failed: 1}
+	})
+
+	It("should format the counts with the rate and elapsed time", func() {
+		Expect(progress.Line(counts, 2*time.Second+300*time.Millisecond)).To(Equal("listed 10, deleted 8, failed 1, 3/s, elapsed 2s"))
+		Expect(progress.Line(progress.Counts{}, 0)).To(Equal("listed 0, deleted 0, failed 0, 0/s, elapsed 0s"))
+	})
+
+	Context("when writing to a log", func() {
+		It("should print a line every interval and the final counts when stopped", func() {
+			display := progress.New(out, false, 10*time.Millisecond)
+			display.Start(read)
+
+			Eventually(out.String).Should(ContainSubstring("deleted 8,"))
+
+			mu.Lock()
+			counts.Deleted = 10
+			mu.Unlock()
+			display.Stop()
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(len(lines)).To(BeNumerically(">=", 2))
+			Expect(lines[len(lines)-1]).To(ContainSubstring("deleted 10,"))
+			Expect(out.String()).NotTo(ContainSubstring("\r"))
+		})
+	})
+
+	Context("when writing to a terminal", func() {
+		It("should redraw the status line in place and end it when stopped", func() {
+			display := progress.New(out, true, 10*time.Millisecond)
+			display.Start(read)
+
+			Eventually(out.String).Should(ContainSubstring("\rlisted 10"))
+			display.Stop()
+
+			Expect(out.String()).To(HaveSuffix("\x1b[K\n"))
+		})
+
+		It("should clear the status line while locked", func() {
+			display := progress.New(out, true, 10*time.Millisecond)
+			display.Start(read)
+			defer display.Stop()
+
+			Eventually(out.String).Should(ContainSubstring("\rlisted 10"))
+
+			display.Lock()
+			Expect(out.String()).To(HaveSuffix("\r\x1b[K"))
+			before := out.String()
+			time.Sleep(30 * time.Millisecond)
+			Expect(out.String()).To(Equal(before))
+			display.Unlock()
+		})
+	})
+})
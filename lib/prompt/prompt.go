@@ -0,0 +1,45 @@
+// Package prompt asks the user questions on the terminal.
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IsTerminal reports whether f is attached to an interactive terminal rather than a pipe or file.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Ask writes the question to w and returns the next line read from r, without surrounding whitespace.
+func Ask(r io.Reader, w io.Writer, question string) (string, error) {
+	// nolint [:gas]
+	fmt.Fprint(w, question)
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.Wrap(err, "package: prompt => func: Ask => method call bufio.Reader.ReadString failed\n")
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks the question and reports whether the answer is exactly the expected text.
+func Confirm(r io.Reader, w io.Writer, question, expected string) (bool, error) {
+	answer, err := Ask(r, w, question)
+	if err != nil {
+		return false, errors.Wrap(err, "package: prompt => func: Confirm => func call prompt.Ask failed\n")
+	}
+
+	return answer == expected, nil
+}
@@ -0,0 +1,13 @@
+package prompt_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPrompt(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Prompt Suite")
+}
@@ -0,0 +1,72 @@
+package prompt_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/GetTerminus/s3helper/lib/prompt"
+)
+
+var _ = Describe("Prompt", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+	})
+
+	Describe("Ask", func() {
+		It("should print the question and return the trimmed answer", func() {
+			answer, err := prompt.Ask(strings.NewReader("  123456 \n"), out, "Token: ")
+			Expect(err).To(BeNil())
+			Expect(answer).To(Equal("123456"))
+			Expect(out.String()).To(Equal("Token: "))
+		})
+
+		It("should accept an answer without a newline", func() {
+			answer, err := prompt.Ask(strings.NewReader("yes"), out, "? ")
+			Expect(err).To(BeNil())
+			Expect(answer).To(Equal("yes"))
+		})
+
+		It("should fail when there is nothing to read", func() {
+			_, err := prompt.Ask(strings.NewReader(""), out, "? ")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("Confirm", func() {
+		It("should confirm when the answer matches", func() {
+			ok, err := prompt.Confirm(strings.NewReader("my-bucket\n"), out, "Type the bucket name: ", "my-bucket")
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should not confirm when the answer differs", func() {
+			ok, err := prompt.Confirm(strings.NewReader("My-Bucket\n"), out, "Type the bucket name: ", "my-bucket")
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should not confirm on a bare yes", func() {
+			ok, err := prompt.Confirm(strings.NewReader("y\n"), out, "Type the bucket name: ", "my-bucket")
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("IsTerminal", func() {
+		It("should not consider a regular file a terminal", func() {
+			f, err := ioutil.TempFile("", "prompt")
+			Expect(err).To(BeNil())
+			defer os.Remove(f.Name())
+			defer f.Close()
+
+			Expect(prompt.IsTerminal(f)).To(BeFalse())
+		})
+	})
+})